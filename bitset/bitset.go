@@ -1,9 +1,11 @@
 // Package bitset provides an interface for bit-string data-structures and
-// also provides a memory-efficient bit-string implementation.
+// also provides a memory-efficient dense bit-string implementation, plus a
+// sparse implementation for bit-strings that differ from a base in few bits.
 package bitset
 
 import (
 	"bytes"
+	"encoding"
 	"fmt"
 )
 
@@ -30,6 +32,57 @@ type BitSet interface {
 	CopyBits(src BitSet, indices []int)
 	// Len returns the length of the bit-string.
 	Len() int
+	// PutUInt32s copies the bit-string into dest as 32-bit words, least
+	// significant word first. Dest must hold at least UInt32Len(Len()) words.
+	PutUInt32s(dest []uint32)
+	// LoadUInt32s overwrites the bit-string in place with the 32-bit words
+	// in src, which uses the layout of PutUInt32s.
+	LoadUInt32s(src []uint32)
+
+	// The operations below combine bit-strings of equal length.
+
+	// And keeps only the bits that are also set in other.
+	And(other BitSet)
+	// Or sets the bits that are set in other.
+	Or(other BitSet)
+	// Xor flips the bits that are set in other.
+	Xor(other BitSet)
+	// AndNot clears the bits that are set in other.
+	AndNot(other BitSet)
+	// CopyMasked copies the bits from src at the positions set in mask.
+	CopyMasked(src, mask BitSet)
+	// Equal tests whether both bit-strings have the same length and bits.
+	Equal(other BitSet) bool
+	// Distance returns the Hamming distance to other.
+	Distance(other BitSet) int
+
+	// Count returns the number of bits set to one.
+	Count() int
+	// Flip inverts the bit at pos.
+	Flip(pos int)
+	// SetRange sets the bits in [start, end) to one.
+	SetRange(start, end int)
+	// ClearRange sets the bits in [start, end) to zero.
+	ClearRange(start, end int)
+	// NextSet returns the first set bit at or after pos, or -1 if there is
+	// none. Iterate with: for i := bs.NextSet(0); i >= 0; i = bs.NextSet(i + 1).
+	NextSet(pos int) int
+	// Clone returns an independent copy of the bit-string.
+	Clone() BitSet
+
+	// Bit-strings are encoded in binary with a version and length header,
+	// and as text in the big-endian notation of FromString. Unmarshalling
+	// replaces both the length and the bits of the bit-string.
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+	encoding.TextMarshaler
+	encoding.TextUnmarshaler
+}
+
+// UInt32Len returns the number of 32-bit words needed to store a bit-string
+// of the given length.
+func UInt32Len(length int) int {
+	return (length + 31) >> 5
 }
 
 func (bs *bitSet) Len() int {
@@ -58,6 +111,33 @@ func (bs *bitSet) CopyBits(src BitSet, indices []int) {
 	}
 }
 
+func (bs *bitSet) PutUInt32s(dest []uint32) {
+	n := UInt32Len(bs.len)
+	for i := 0; i < n; i++ {
+		dest[i] = uint32(bs.array[i>>1] >> ((uint(i) & 1) << 5))
+	}
+}
+
+func (bs *bitSet) LoadUInt32s(src []uint32) {
+	n := UInt32Len(bs.len)
+	for i := range bs.array {
+		w := uint64(src[i<<1])
+		if i<<1+1 < n {
+			w |= uint64(src[i<<1+1]) << 32
+		}
+		bs.array[i] = w
+	}
+	bs.trim()
+}
+
+// Function trim clears the unused bits of the last word, so words can be
+// compared and counted without masking.
+func (bs *bitSet) trim() {
+	if r := uint(bs.len) & mod; r != 0 {
+		bs.array[len(bs.array)-1] &= (1 << r) - 1
+	}
+}
+
 func (bs *bitSet) Has(pos int) bool {
 	return (bs.array[pos>>pow]&(1<<(uint(pos)&mod)) != 0)
 }
@@ -66,7 +146,7 @@ func (bs *bitSet) String() string {
 	var buffer bytes.Buffer
 	skipFirst := 0
 	if bs.len%64 != 0 {
-		format := fmt.Sprintf("%c0%db", '%', uint((bs.len)+1)%64-1)
+		format := fmt.Sprintf("%c0%db", '%', uint(bs.len)&mod)
 		buffer.WriteString(fmt.Sprintf(format, bs.array[len(bs.array)-1]))
 		skipFirst = 1
 	}
@@ -95,6 +175,8 @@ func FromString(s string) (BitSet, error) {
 	return b, nil
 }
 
+// FromUInt32s converts 32-bit words, least significant word first, to a new
+// bit-set. Use LoadUInt32s to reuse an existing bit-set instead.
 func FromUInt32s(ints []uint32, length int) (BitSet, error) {
 	r := make([]uint64, 1+((length-1)>>6))
 	for i, _ := range r {
@@ -103,5 +185,7 @@ func FromUInt32s(ints []uint32, length int) (BitSet, error) {
 			r[i] += (uint64(ints[i<<1+1]) << 32)
 		}
 	}
-	return &bitSet{length, r}, nil
+	bs := &bitSet{length, r}
+	bs.trim()
+	return bs, nil
 }
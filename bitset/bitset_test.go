@@ -2,21 +2,56 @@ package bitset
 
 import (
 	"fmt"
+	"math/rand"
 	"testing"
 )
 
+// Implementations converts a dense bit-set to every implementation of the
+// BitSet interface, so all tests run against each of them.
+var implementations = []struct {
+	name    string
+	convert func(BitSet) BitSet
+}{
+	{"dense", func(bs BitSet) BitSet { return bs }},
+	{"sparse", func(bs BitSet) BitSet {
+		s := NewSparse(bs.Len())
+		s.Or(bs)
+		return s
+	}},
+	{"delta", func(bs BitSet) BitSet {
+		// Use a base with alternating bits, so the delta is non-trivial.
+		base := New(bs.Len())
+		for i := 0; i < bs.Len(); i += 2 {
+			base.Set(i)
+		}
+		d := NewDelta(base)
+		for i := 0; i < bs.Len(); i++ {
+			d.CopyBit(bs, i)
+		}
+		return d
+	}},
+}
+
+// Function fromString parses s with FromString and converts the result to
+// the given implementation.
+func fromString(t *testing.T, convert func(BitSet) BitSet, s string) BitSet {
+	bs, err := FromString(s)
+	testParseError(t, s, err)
+	return convert(bs)
+}
+
 var parsingTests = []string{
 	"11111", "00000", "10101", "10000", "00001",
 }
 
 func TestParsing(t *testing.T) {
-	for _, s := range parsingTests {
-		bs, err := FromString(s)
-		testParseError(t, s, err)
-		if actual := fmt.Sprint(bs); actual != s {
-			t.Logf("%b %b %b %b", 1, 3, 8, 234235)
-			t.Errorf("FromString(%q).String() = %q, expected %q.",
-				s, actual, s)
+	for _, impl := range implementations {
+		for _, s := range parsingTests {
+			bs := fromString(t, impl.convert, s)
+			if actual := fmt.Sprint(bs); actual != s {
+				t.Errorf("%s: FromString(%q).String() = %q, expected %q.",
+					impl.name, s, actual, s)
+			}
 		}
 	}
 }
@@ -37,19 +72,19 @@ func testParseError(t *testing.T, s string, err error) {
 }
 
 func TestModification(t *testing.T) {
-	for _, test := range modificationTests {
-		bs, err := FromString(test.input)
+	for _, impl := range implementations {
+		for _, test := range modificationTests {
+			bs := fromString(t, impl.convert, test.input)
 
-		testParseError(t, test.input, err)
-
-		if test.index < 0 {
-			bs.Clear(-test.index)
-		} else {
-			bs.Set(test.index)
-		}
-		if actual := fmt.Sprint(bs); actual != test.expected {
-			t.Errorf("Set/Clear(%q, %d) = %q, expected %q.",
-				test.input, test.index, actual, test.expected)
+			if test.index < 0 {
+				bs.Clear(-test.index)
+			} else {
+				bs.Set(test.index)
+			}
+			if actual := fmt.Sprint(bs); actual != test.expected {
+				t.Errorf("%s: Set/Clear(%q, %d) = %q, expected %q.",
+					impl.name, test.input, test.index, actual, test.expected)
+			}
 		}
 	}
 }
@@ -64,15 +99,15 @@ var hasTests = []struct {
 }
 
 func TestSelection(t *testing.T) {
-	for _, test := range hasTests {
-		bs, err := FromString(test.input)
+	for _, impl := range implementations {
+		for _, test := range hasTests {
+			bs := fromString(t, impl.convert, test.input)
 
-		testParseError(t, test.input, err)
-
-		actual := bs.Has(test.index)
-		if actual != test.expected {
-			t.Errorf("Has(%q, %d) = %q, expected %q.",
-				test.input, test.index, actual, test.expected)
+			actual := bs.Has(test.index)
+			if actual != test.expected {
+				t.Errorf("%s: Has(%q, %d) = %t, expected %t.",
+					impl.name, test.input, test.index, actual, test.expected)
+			}
 		}
 	}
 }
@@ -90,17 +125,149 @@ var copyTests = []struct {
 }
 
 func TestCopy(t *testing.T) {
-	for _, test := range copyTests {
-		src, err := FromString(test.src)
-		testParseError(t, test.src, err)
+	// Copy between every pair of implementations, including mixed pairs.
+	for _, srcImpl := range implementations {
+		for _, destImpl := range implementations {
+			for _, test := range copyTests {
+				src := fromString(t, srcImpl.convert, test.src)
+				dest := fromString(t, destImpl.convert, test.dest)
+
+				dest.CopyBits(src, test.indices)
+				if actual := fmt.Sprint(dest); actual != test.expected {
+					t.Errorf("%s/%s: CopyBits(%q %q %v) = %q, expected %q.",
+						srcImpl.name, destImpl.name, test.src, test.dest,
+						test.indices, actual, test.expected)
+				}
+			}
+		}
+	}
+}
+
+// Function randomBitSet returns a dense bit-set with uniformly random bits.
+func randomBitSet(r *rand.Rand, length int) BitSet {
+	bs := New(length)
+	for i := 0; i < length; i++ {
+		if r.Intn(2) == 1 {
+			bs.Set(i)
+		}
+	}
+	return bs
+}
+
+// Function putUInt32sPerBit is the reference conversion to 32-bit words,
+// testing every bit individually.
+func putUInt32sPerBit(bs BitSet, dest []uint32) {
+	for i := range dest[:UInt32Len(bs.Len())] {
+		dest[i] = 0
+	}
+	for i := 0; i < bs.Len(); i++ {
+		if bs.Has(i) {
+			dest[i>>5] |= 1 << (uint(i) & 31)
+		}
+	}
+}
+
+func TestUInt32s(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for length := 1; length <= 200; length++ {
+		bs := randomBitSet(r, length)
+
+		expected := make([]uint32, UInt32Len(length))
+		putUInt32sPerBit(bs, expected)
+
+		actual := make([]uint32, UInt32Len(length))
+		bs.PutUInt32s(actual)
 
-		dest, err := FromString(test.dest)
-		testParseError(t, test.dest, err)
+		for i := range expected {
+			if actual[i] != expected[i] {
+				t.Fatalf("PutUInt32s(%v)[%d] = %x, expected %x.", bs, i, actual[i], expected[i])
+			}
+		}
+
+		converted, _ := FromUInt32s(actual, length)
+		if fmt.Sprint(converted) != fmt.Sprint(bs) {
+			t.Fatalf("FromUInt32s(%x) = %v, expected %v.", actual, converted, bs)
+		}
+
+		for _, impl := range implementations {
+			other := impl.convert(New(length))
+			other.LoadUInt32s(actual)
+			if fmt.Sprint(other) != fmt.Sprint(bs) {
+				t.Fatalf("%s: LoadUInt32s(%x) = %v, expected %v.", impl.name, actual, other, bs)
+			}
 
-		dest.CopyBits(src, test.indices)
-		if actual := fmt.Sprint(dest); actual != test.expected {
-			t.Errorf("CopyBits(%q %q %v) = %q, expected %q.",
-				test.src, test.dest, test.indices, actual, test.expected)
+			words := make([]uint32, len(actual))
+			impl.convert(bs).PutUInt32s(words)
+			for i := range words {
+				if words[i] != expected[i] {
+					t.Fatalf("%s: PutUInt32s(%v)[%d] = %x, expected %x.", impl.name, bs, i, words[i], expected[i])
+				}
+			}
 		}
 	}
 }
+
+func TestLoadUInt32sTrims(t *testing.T) {
+	bs := New(33)
+	bs.LoadUInt32s([]uint32{0xffffffff, 0xffffffff})
+
+	words := make([]uint32, 2)
+	bs.PutUInt32s(words)
+
+	if words[0] != 0xffffffff || words[1] != 1 {
+		t.Errorf("LoadUInt32s kept bits beyond the length: %x.", words)
+	}
+}
+
+func TestUInt32sAllocations(t *testing.T) {
+	bs := randomBitSet(rand.New(rand.NewSource(1)), 1000)
+	words := make([]uint32, UInt32Len(bs.Len()))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		bs.PutUInt32s(words)
+		bs.LoadUInt32s(words)
+	})
+	if allocs != 0 {
+		t.Errorf("PutUInt32s and LoadUInt32s allocated %v times, expected 0.", allocs)
+	}
+}
+
+func benchmarkBitSet(b *testing.B) (BitSet, []uint32) {
+	bs := randomBitSet(rand.New(rand.NewSource(1)), 1000)
+	words := make([]uint32, UInt32Len(bs.Len()))
+	bs.PutUInt32s(words)
+	return bs, words
+}
+
+func BenchmarkPutUInt32sPerBit(b *testing.B) {
+	bs, words := benchmarkBitSet(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		putUInt32sPerBit(bs, words)
+	}
+}
+
+func BenchmarkPutUInt32s(b *testing.B) {
+	bs, words := benchmarkBitSet(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bs.PutUInt32s(words)
+	}
+}
+
+func BenchmarkFromUInt32s(b *testing.B) {
+	bs, words := benchmarkBitSet(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		FromUInt32s(words, bs.Len())
+	}
+}
+
+func BenchmarkLoadUInt32s(b *testing.B) {
+	bs, words := benchmarkBitSet(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bs.LoadUInt32s(words)
+	}
+}
@@ -0,0 +1,112 @@
+package bitset
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+)
+
+// The version of the binary encoding, stored in its first byte.
+const binaryVersion byte = 1
+
+// MarshalBinary encodes the bit-string as a version byte, the length as an
+// unsigned varint and the bits packed into bytes, least significant first.
+func (bs *bitSet) MarshalBinary() ([]byte, error) {
+	numBytes := (bs.len + 7) >> 3
+	data := make([]byte, 1, 1+binary.MaxVarintLen64+numBytes)
+	data[0] = binaryVersion
+	data = binary.AppendUvarint(data, uint64(bs.len))
+
+	for i := 0; i < numBytes; i++ {
+		data = append(data, byte(bs.array[i>>3]>>((uint(i)&7)<<3)))
+	}
+	return data, nil
+}
+
+// UnmarshalBinary decodes a bit-string encoded by MarshalBinary.
+func (bs *bitSet) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return fmt.Errorf("bitset: empty binary encoding")
+	}
+	if data[0] != binaryVersion {
+		return fmt.Errorf("bitset: unsupported binary encoding version %d", data[0])
+	}
+
+	length, n := binary.Uvarint(data[1:])
+	if n <= 0 {
+		return fmt.Errorf("bitset: invalid length in binary encoding")
+	}
+	data = data[1+n:]
+
+	// Rejecting lengths beyond the bits present keeps the rounding below
+	// from overflowing and the length within an int.
+	if length > 8*uint64(len(data)) || uint64(len(data)) != (length+7)>>3 {
+		format := "bitset: binary encoding has %d bytes for length %d"
+		return fmt.Errorf(format, len(data), length)
+	}
+
+	bs.len = int(length)
+	bs.array = make([]uint64, (bs.len+63)/64)
+	for i, b := range data {
+		bs.array[i>>3] |= uint64(b) << ((uint(i) & 7) << 3)
+	}
+	bs.trim()
+	return nil
+}
+
+// MarshalText encodes the bit-string in the big-endian notation of
+// FromString. It is also used for the JSON encoding.
+func (bs *bitSet) MarshalText() ([]byte, error) {
+	return []byte(bs.String()), nil
+}
+
+// UnmarshalText decodes a bit-string in the notation of FromString.
+func (bs *bitSet) UnmarshalText(text []byte) error {
+	decoded, err := FromString(string(text))
+	if err != nil {
+		return err
+	}
+	*bs = *decoded.(*bitSet)
+	return nil
+}
+
+// Hex returns the binary encoding of the bit-string in hexadecimal.
+func Hex(bs BitSet) string {
+	data, _ := bs.MarshalBinary()
+	return hex.EncodeToString(data)
+}
+
+// FromHex decodes a bit-string encoded by Hex.
+func FromHex(s string) (BitSet, error) {
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("bitset: invalid hex encoding: %v", err)
+	}
+	return FromBinary(data)
+}
+
+// Base64 returns the binary encoding of the bit-string in standard base64.
+func Base64(bs BitSet) string {
+	data, _ := bs.MarshalBinary()
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+// FromBase64 decodes a bit-string encoded by Base64.
+func FromBase64(s string) (BitSet, error) {
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("bitset: invalid base64 encoding: %v", err)
+	}
+	return FromBinary(data)
+}
+
+// FromBinary decodes a bit-string encoded by MarshalBinary into a new
+// dense bit-set.
+func FromBinary(data []byte) (BitSet, error) {
+	bs := new(bitSet)
+	if err := bs.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return bs, nil
+}
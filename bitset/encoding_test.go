@@ -0,0 +1,128 @@
+package bitset
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+var encodingLengths = []int{0, 1, 7, 8, 9, 63, 64, 65, 127, 128, 200}
+
+func TestString(t *testing.T) {
+	for _, length := range encodingLengths {
+		bs := New(length)
+		if length > 0 {
+			bs.Set(0)
+			bs.Set(length - 1)
+		}
+
+		expected := []byte(strings.Repeat("0", length))
+		if length > 0 {
+			expected[0], expected[length-1] = '1', '1'
+		}
+
+		if actual := fmt.Sprint(bs); actual != string(expected) {
+			t.Errorf("String() of length %d = %q, expected %q.", length, actual, expected)
+		}
+	}
+}
+
+func TestEncodingRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for _, length := range encodingLengths {
+		for _, impl := range implementations {
+			testEncodingRoundTrip(t, impl.name, impl.convert(randomBitSet(r, length)))
+		}
+	}
+}
+
+func testEncodingRoundTrip(t *testing.T, name string, bs BitSet) {
+	data, err := bs.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%s: MarshalBinary() returned error %q.", name, err)
+	}
+
+	decoded := []struct {
+		name string
+		bits func() (BitSet, error)
+	}{
+		{"FromBinary", func() (BitSet, error) { return FromBinary(data) }},
+		{"FromHex", func() (BitSet, error) { return FromHex(Hex(bs)) }},
+		{"FromBase64", func() (BitSet, error) { return FromBase64(Base64(bs)) }},
+		{"sparse UnmarshalBinary", func() (BitSet, error) {
+			d := NewSparse(0)
+			return d, d.UnmarshalBinary(data)
+		}},
+		{"UnmarshalText", func() (BitSet, error) {
+			text, _ := bs.MarshalText()
+			d := New(0)
+			return d, d.UnmarshalText(text)
+		}},
+		{"json.Unmarshal", func() (BitSet, error) {
+			text, err := json.Marshal(bs)
+			if err != nil {
+				return nil, err
+			}
+			d := New(0)
+			return d, json.Unmarshal(text, d)
+		}},
+	}
+
+	for _, test := range decoded {
+		actual, err := test.bits()
+		if err != nil {
+			t.Errorf("%s: %s of length %d returned error %q.", name, test.name, bs.Len(), err)
+		} else if !actual.Equal(bs) {
+			t.Errorf("%s: %s of length %d = %v, expected %v.", name, test.name, bs.Len(), actual, bs)
+		}
+	}
+}
+
+var invalidBinaryTests = [][]byte{
+	{},
+	{2, 0},
+	{binaryVersion},
+	{binaryVersion, 9, 0xff},
+	{binaryVersion, 8, 0xff, 0xff},
+
+	// A length near 2^64 overflows when rounded up to bytes.
+	{binaryVersion, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01},
+}
+
+func TestInvalidBinary(t *testing.T) {
+	for _, data := range invalidBinaryTests {
+		if _, err := FromBinary(data); err == nil {
+			t.Errorf("FromBinary(%x) did not return an error.", data)
+		}
+	}
+}
+
+func TestBinaryFormat(t *testing.T) {
+	bs, _ := FromString("1000000001")
+	if actual, expected := Hex(bs), "010a0102"; actual != expected {
+		t.Errorf("Hex(%v) = %q, expected %q.", bs, actual, expected)
+	}
+}
+
+func FuzzFromBinary(f *testing.F) {
+	for _, data := range invalidBinaryTests {
+		f.Add(data)
+	}
+	f.Add([]byte{binaryVersion, 10, 0x01, 0x02})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		bs, err := FromBinary(data)
+		if err != nil {
+			return
+		}
+
+		// Every decoded bit-string survives another round trip.
+		encoded, _ := bs.MarshalBinary()
+		if decoded, err := FromBinary(encoded); err != nil || !decoded.Equal(bs) {
+			t.Errorf("FromBinary(%x) = %v, which does not round-trip.", data, bs)
+		}
+	})
+}
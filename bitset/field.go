@@ -0,0 +1,37 @@
+package bitset
+
+// FieldWidth returns the number of bits of a field holding the values 0 to
+// alphabet-1. The width is a power of two, so fields of equal width never
+// straddle a 32-bit word.
+func FieldWidth(alphabet int) int {
+	width := 1
+	for 1<<uint(width) < alphabet {
+		width <<= 1
+	}
+	return width
+}
+
+// Field returns the value of field i of a bit-string packed into fields of
+// the given width, least significant bit first.
+func Field(bs BitSet, i, width int) int {
+	value := 0
+	for j := width - 1; j >= 0; j-- {
+		value <<= 1
+		if bs.Has(i*width + j) {
+			value |= 1
+		}
+	}
+	return value
+}
+
+// SetField sets field i of a bit-string packed into fields of the given
+// width to value.
+func SetField(bs BitSet, i, width, value int) {
+	for j := 0; j < width; j++ {
+		if value&(1<<uint(j)) != 0 {
+			bs.Set(i*width + j)
+		} else {
+			bs.Clear(i*width + j)
+		}
+	}
+}
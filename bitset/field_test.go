@@ -0,0 +1,43 @@
+package bitset
+
+import (
+	"fmt"
+	"testing"
+)
+
+var fieldWidthTests = []struct {
+	alphabet int
+	width    int
+}{
+	{0, 1}, {1, 1}, {2, 1}, {3, 2}, {4, 2}, {5, 4}, {16, 4}, {17, 8}, {256, 8},
+}
+
+func TestFieldWidth(t *testing.T) {
+	for _, test := range fieldWidthTests {
+		if actual := FieldWidth(test.alphabet); actual != test.width {
+			t.Errorf("FieldWidth(%d) = %d, expected %d.", test.alphabet, actual, test.width)
+		}
+	}
+}
+
+func TestFields(t *testing.T) {
+	for _, width := range []int{1, 2, 4, 8} {
+		bs := New(10 * width)
+		for i := 0; i < 10; i++ {
+			SetField(bs, i, width, (i*7)%(1<<uint(width)))
+		}
+		for i := 0; i < 10; i++ {
+			expected := (i * 7) % (1 << uint(width))
+			if actual := Field(bs, i, width); actual != expected {
+				t.Errorf("Field(%v, %d, %d) = %d, expected %d.", bs, i, width, actual, expected)
+			}
+		}
+	}
+
+	// Fields are packed least significant bit first.
+	bs := New(8)
+	SetField(bs, 1, 4, 6)
+	if fmt.Sprint(bs) != "01100000" {
+		t.Errorf("SetField(1, 4, 6) = %v, expected 01100000.", bs)
+	}
+}
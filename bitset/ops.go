@@ -0,0 +1,200 @@
+package bitset
+
+import (
+	"math/bits"
+)
+
+// Function dense returns the dense implementation behind other, so
+// operations between dense bit-sets can work on whole words.
+func dense(other BitSet) (*bitSet, bool) {
+	o, ok := other.(*bitSet)
+	return o, ok
+}
+
+// The functions below implement the operations for any pair of BitSet
+// implementations, visiting only the set bits through NextSet.
+
+func and(bs, other BitSet) {
+	for i := bs.NextSet(0); i >= 0; i = bs.NextSet(i + 1) {
+		if !other.Has(i) {
+			bs.Clear(i)
+		}
+	}
+}
+
+func or(bs, other BitSet) {
+	for i := other.NextSet(0); i >= 0; i = other.NextSet(i + 1) {
+		bs.Set(i)
+	}
+}
+
+func xor(bs, other BitSet) {
+	for i := other.NextSet(0); i >= 0; i = other.NextSet(i + 1) {
+		bs.Flip(i)
+	}
+}
+
+func andNot(bs, other BitSet) {
+	for i := other.NextSet(0); i >= 0; i = other.NextSet(i + 1) {
+		bs.Clear(i)
+	}
+}
+
+func copyMasked(bs, src, mask BitSet) {
+	for i := mask.NextSet(0); i >= 0; i = mask.NextSet(i + 1) {
+		bs.CopyBit(src, i)
+	}
+}
+
+func distance(a, b BitSet) int {
+	d := 0
+	i, j := a.NextSet(0), b.NextSet(0)
+	for i >= 0 || j >= 0 {
+		switch {
+		case j < 0 || (i >= 0 && i < j):
+			d++
+			i = a.NextSet(i + 1)
+		case i < 0 || j < i:
+			d++
+			j = b.NextSet(j + 1)
+		default:
+			i, j = a.NextSet(i+1), b.NextSet(j+1)
+		}
+	}
+	return d
+}
+
+func (bs *bitSet) And(other BitSet) {
+	if o, ok := dense(other); ok {
+		for i := range bs.array {
+			bs.array[i] &= o.array[i]
+		}
+		return
+	}
+	and(bs, other)
+}
+
+func (bs *bitSet) Or(other BitSet) {
+	if o, ok := dense(other); ok {
+		for i := range bs.array {
+			bs.array[i] |= o.array[i]
+		}
+		return
+	}
+	or(bs, other)
+}
+
+func (bs *bitSet) Xor(other BitSet) {
+	if o, ok := dense(other); ok {
+		for i := range bs.array {
+			bs.array[i] ^= o.array[i]
+		}
+		return
+	}
+	xor(bs, other)
+}
+
+func (bs *bitSet) AndNot(other BitSet) {
+	if o, ok := dense(other); ok {
+		for i := range bs.array {
+			bs.array[i] &^= o.array[i]
+		}
+		return
+	}
+	andNot(bs, other)
+}
+
+func (bs *bitSet) CopyMasked(src, mask BitSet) {
+	s, sok := dense(src)
+	m, mok := dense(mask)
+	if sok && mok {
+		for i := range bs.array {
+			bs.array[i] = (bs.array[i] &^ m.array[i]) | (s.array[i] & m.array[i])
+		}
+		return
+	}
+	copyMasked(bs, src, mask)
+}
+
+func (bs *bitSet) Equal(other BitSet) bool {
+	if bs.len != other.Len() {
+		return false
+	}
+	return bs.Distance(other) == 0
+}
+
+func (bs *bitSet) Distance(other BitSet) int {
+	if o, ok := dense(other); ok {
+		d := 0
+		for i := range bs.array {
+			d += bits.OnesCount64(bs.array[i] ^ o.array[i])
+		}
+		return d
+	}
+	return distance(bs, other)
+}
+
+func (bs *bitSet) Count() int {
+	c := 0
+	for _, w := range bs.array {
+		c += bits.OnesCount64(w)
+	}
+	return c
+}
+
+func (bs *bitSet) Flip(pos int) {
+	bs.array[pos>>pow] ^= (1 << (uint(pos) & mod))
+}
+
+// Function rangeMask calls f with every word index and mask of the bits
+// in [start, end).
+func rangeMask(start, end int, f func(i int, mask uint64)) {
+	if start >= end {
+		return
+	}
+	first, last := start>>pow, (end-1)>>pow
+	lo := ^uint64(0) << (uint(start) & mod)
+	hi := ^uint64(0) >> (mod - (uint(end-1) & mod))
+	if first == last {
+		f(first, lo&hi)
+		return
+	}
+	f(first, lo)
+	for i := first + 1; i < last; i++ {
+		f(i, ^uint64(0))
+	}
+	f(last, hi)
+}
+
+func (bs *bitSet) SetRange(start, end int) {
+	rangeMask(start, end, func(i int, mask uint64) {
+		bs.array[i] |= mask
+	})
+}
+
+func (bs *bitSet) ClearRange(start, end int) {
+	rangeMask(start, end, func(i int, mask uint64) {
+		bs.array[i] &^= mask
+	})
+}
+
+func (bs *bitSet) NextSet(pos int) int {
+	if pos >= bs.len {
+		return -1
+	}
+	i := pos >> pow
+	w := bs.array[i] >> (uint(pos) & mod)
+	if w != 0 {
+		return pos + bits.TrailingZeros64(w)
+	}
+	for i++; i < len(bs.array); i++ {
+		if bs.array[i] != 0 {
+			return i<<pow + bits.TrailingZeros64(bs.array[i])
+		}
+	}
+	return -1
+}
+
+func (bs *bitSet) Clone() BitSet {
+	return &bitSet{bs.len, append([]uint64(nil), bs.array...)}
+}
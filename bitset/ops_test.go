@@ -0,0 +1,154 @@
+package bitset
+
+import (
+	"testing"
+)
+
+// Function fromBytes builds a bit-set and its naive []bool reference from
+// the bits of data.
+func fromBytes(data []byte, length int) (BitSet, []bool) {
+	bs := New(length)
+	ref := make([]bool, length)
+	for i := 0; i < length; i++ {
+		if data[i>>3]&(1<<(uint(i)&7)) != 0 {
+			bs.Set(i)
+			ref[i] = true
+		}
+	}
+	return bs, ref
+}
+
+// Function compare reports a test failure if the bit-set and its reference
+// differ in any bit.
+func compare(t *testing.T, op string, bs BitSet, ref []bool) {
+	if bs.Len() != len(ref) {
+		t.Fatalf("%s: Len() = %d, expected %d.", op, bs.Len(), len(ref))
+	}
+	for i, v := range ref {
+		if bs.Has(i) != v {
+			t.Fatalf("%s: Has(%d) = %t, expected %t.", op, i, !v, v)
+		}
+	}
+}
+
+func FuzzOps(f *testing.F) {
+	f.Add([]byte{0xff, 0x00, 0x0f}, uint8(0), uint16(0), uint16(8))
+	f.Add([]byte{0xa5, 0x5a, 0x3c, 0xc3, 0x01, 0x80}, uint8(3), uint16(1), uint16(9))
+	f.Add(make([]byte, 48), uint8(5), uint16(60), uint16(70))
+
+	// Seed every combination of implementations for the three operands.
+	for impl := 0; impl < 27; impl++ {
+		f.Add([]byte{0x96, 0x0f, 0xf0, 0x33, 0xcc, 0x55, 0x3c, 0xc3, 0xaa}, uint8(impl<<3|impl%8), uint16(impl), uint16(2*impl))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte, cut uint8, start, end uint16) {
+		n := len(data) / 3
+		length := n*8 - int(cut%8)
+		if length <= 0 {
+			return
+		}
+
+		// Pick the implementations of the operands from the input.
+		xd, xr := fromBytes(data[:n], length)
+		yd, yr := fromBytes(data[n:2*n], length)
+		md, mr := fromBytes(data[2*n:3*n], length)
+
+		impl := int(cut) >> 3
+		x := implementations[impl%len(implementations)].convert(xd)
+		y := implementations[impl/3%len(implementations)].convert(yd)
+		m := implementations[impl/9%len(implementations)].convert(md)
+
+		binary := []struct {
+			name string
+			op   func(BitSet, BitSet)
+			ref  func(a, b bool) bool
+		}{
+			{"And", BitSet.And, func(a, b bool) bool { return a && b }},
+			{"Or", BitSet.Or, func(a, b bool) bool { return a || b }},
+			{"Xor", BitSet.Xor, func(a, b bool) bool { return a != b }},
+			{"AndNot", BitSet.AndNot, func(a, b bool) bool { return a && !b }},
+		}
+
+		for _, test := range binary {
+			actual := x.Clone()
+			test.op(actual, y)
+			expected := make([]bool, length)
+			for i := range expected {
+				expected[i] = test.ref(xr[i], yr[i])
+			}
+			compare(t, test.name, actual, expected)
+		}
+
+		masked := x.Clone()
+		masked.CopyMasked(y, m)
+		expected := make([]bool, length)
+		for i := range expected {
+			expected[i] = xr[i]
+			if mr[i] {
+				expected[i] = yr[i]
+			}
+		}
+		compare(t, "CopyMasked", masked, expected)
+
+		count, distance := 0, 0
+		for i := range xr {
+			if xr[i] {
+				count++
+			}
+			if xr[i] != yr[i] {
+				distance++
+			}
+		}
+		if actual := x.Count(); actual != count {
+			t.Fatalf("Count() = %d, expected %d.", actual, count)
+		}
+		if actual := x.Distance(y); actual != distance {
+			t.Fatalf("Distance() = %d, expected %d.", actual, distance)
+		}
+		if actual := x.Equal(y); actual != (distance == 0) {
+			t.Fatalf("Equal() = %t, expected %t.", actual, distance == 0)
+		}
+		if !x.Equal(x.Clone()) {
+			t.Fatalf("Equal(Clone()) = false, expected true.")
+		}
+
+		lo, hi := int(start)%(length+1), int(end)%(length+1)
+		set, clear := x.Clone(), x.Clone()
+		set.SetRange(lo, hi)
+		clear.ClearRange(lo, hi)
+		setRef := append([]bool(nil), xr...)
+		clearRef := append([]bool(nil), xr...)
+		for i := lo; i < hi; i++ {
+			setRef[i], clearRef[i] = true, false
+		}
+		compare(t, "SetRange", set, setRef)
+		compare(t, "ClearRange", clear, clearRef)
+
+		flipped := x.Clone()
+		flipRef := append([]bool(nil), xr...)
+		for i := 0; i < length; i += 3 {
+			flipped.Flip(i)
+			flipRef[i] = !flipRef[i]
+		}
+		compare(t, "Flip", flipped, flipRef)
+		compare(t, "Clone", x, xr)
+
+		next := 0
+		for i := x.NextSet(0); i >= 0; i = x.NextSet(i + 1) {
+			for ; next < i; next++ {
+				if xr[next] {
+					t.Fatalf("NextSet skipped bit %d.", next)
+				}
+			}
+			if !xr[i] {
+				t.Fatalf("NextSet returned unset bit %d.", i)
+			}
+			next = i + 1
+		}
+		for ; next < length; next++ {
+			if xr[next] {
+				t.Fatalf("NextSet skipped bit %d.", next)
+			}
+		}
+	})
+}
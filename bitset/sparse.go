@@ -0,0 +1,282 @@
+package bitset
+
+import (
+	"math/bits"
+	"sort"
+)
+
+// The sparse implementation stores the sorted positions at which the
+// bit-string differs from a base bit-string. Without a base it differs
+// from the all-zero bit-string, i.e. it stores the positions of set bits.
+type sparseBitSet struct {
+	len  int
+	base BitSet
+	diff []int
+}
+
+// NewSparse returns an all-zero bit-string that only stores its set bits.
+func NewSparse(len int) BitSet {
+	return &sparseBitSet{len: len}
+}
+
+// NewDelta returns a bit-string equal to base that only stores the
+// positions where it differs from base. The base is shared, not copied,
+// so it must not be modified while the delta is in use.
+func NewDelta(base BitSet) BitSet {
+	return &sparseBitSet{len: base.Len(), base: base}
+}
+
+func (bs *sparseBitSet) Len() int {
+	return bs.len
+}
+
+// Function search returns the index in diff of the first position at or
+// after pos.
+func (bs *sparseBitSet) search(pos int) int {
+	return sort.SearchInts(bs.diff, pos)
+}
+
+func (bs *sparseBitSet) differs(pos int) bool {
+	i := bs.search(pos)
+	return i < len(bs.diff) && bs.diff[i] == pos
+}
+
+func (bs *sparseBitSet) baseHas(pos int) bool {
+	return bs.base != nil && bs.base.Has(pos)
+}
+
+func (bs *sparseBitSet) Has(pos int) bool {
+	return bs.baseHas(pos) != bs.differs(pos)
+}
+
+func (bs *sparseBitSet) Flip(pos int) {
+	i := bs.search(pos)
+	if i < len(bs.diff) && bs.diff[i] == pos {
+		bs.diff = append(bs.diff[:i], bs.diff[i+1:]...)
+		return
+	}
+	bs.diff = append(bs.diff, 0)
+	copy(bs.diff[i+1:], bs.diff[i:])
+	bs.diff[i] = pos
+}
+
+func (bs *sparseBitSet) Set(pos int) {
+	if !bs.Has(pos) {
+		bs.Flip(pos)
+	}
+}
+
+func (bs *sparseBitSet) Clear(pos int) {
+	if bs.Has(pos) {
+		bs.Flip(pos)
+	}
+}
+
+func (bs *sparseBitSet) CopyBit(src BitSet, index int) {
+	if src.Has(index) {
+		bs.Set(index)
+	} else {
+		bs.Clear(index)
+	}
+}
+
+func (bs *sparseBitSet) CopyBits(src BitSet, indices []int) {
+	for _, index := range indices {
+		bs.CopyBit(src, index)
+	}
+}
+
+func (bs *sparseBitSet) NextSet(pos int) int {
+	if pos >= bs.len {
+		return -1
+	}
+
+	i := bs.search(pos)
+	if bs.base == nil {
+		if i < len(bs.diff) {
+			return bs.diff[i]
+		}
+		return -1
+	}
+
+	// Merge the set bits of the base with the differences, skipping the
+	// positions where both cancel out.
+	b := bs.base.NextSet(pos)
+	for {
+		d := -1
+		if i < len(bs.diff) {
+			d = bs.diff[i]
+		}
+		switch {
+		case d < 0:
+			return b
+		case b < 0 || d < b:
+			return d
+		case b < d:
+			return b
+		}
+		b, i = bs.base.NextSet(b+1), i+1
+	}
+}
+
+func (bs *sparseBitSet) Count() int {
+	if bs.base == nil {
+		return len(bs.diff)
+	}
+	c := bs.base.Count()
+	for _, pos := range bs.diff {
+		if bs.base.Has(pos) {
+			c--
+		} else {
+			c++
+		}
+	}
+	return c
+}
+
+func (bs *sparseBitSet) SetRange(start, end int) {
+	for i := start; i < end; i++ {
+		bs.Set(i)
+	}
+}
+
+func (bs *sparseBitSet) ClearRange(start, end int) {
+	for i := start; i < end; i++ {
+		bs.Clear(i)
+	}
+}
+
+func (bs *sparseBitSet) And(other BitSet) {
+	and(bs, other)
+}
+
+func (bs *sparseBitSet) Or(other BitSet) {
+	or(bs, other)
+}
+
+func (bs *sparseBitSet) Xor(other BitSet) {
+	xor(bs, other)
+}
+
+func (bs *sparseBitSet) AndNot(other BitSet) {
+	andNot(bs, other)
+}
+
+func (bs *sparseBitSet) CopyMasked(src, mask BitSet) {
+	copyMasked(bs, src, mask)
+}
+
+func (bs *sparseBitSet) Equal(other BitSet) bool {
+	if bs.len != other.Len() {
+		return false
+	}
+	return bs.Distance(other) == 0
+}
+
+func (bs *sparseBitSet) Distance(other BitSet) int {
+	// Deltas of the same base only differ in their differences.
+	if o, ok := other.(*sparseBitSet); ok && o.base == bs.base {
+		d, i, j := 0, 0, 0
+		for i < len(bs.diff) && j < len(o.diff) {
+			switch {
+			case bs.diff[i] < o.diff[j]:
+				d, i = d+1, i+1
+			case o.diff[j] < bs.diff[i]:
+				d, j = d+1, j+1
+			default:
+				i, j = i+1, j+1
+			}
+		}
+		return d + len(bs.diff) - i + len(o.diff) - j
+	}
+	return distance(bs, other)
+}
+
+func (bs *sparseBitSet) Clone() BitSet {
+	return &sparseBitSet{bs.len, bs.base, append([]int(nil), bs.diff...)}
+}
+
+func (bs *sparseBitSet) PutUInt32s(dest []uint32) {
+	n := UInt32Len(bs.len)
+	for i := 0; i < n; i++ {
+		dest[i] = 0
+	}
+	for i := bs.NextSet(0); i >= 0; i = bs.NextSet(i + 1) {
+		dest[i>>5] |= 1 << (uint(i) & 31)
+	}
+}
+
+func (bs *sparseBitSet) LoadUInt32s(src []uint32) {
+	bs.diff = bs.diff[:0]
+
+	if bs.base == nil {
+		for i, w := range src[:UInt32Len(bs.len)] {
+			for w != 0 {
+				pos := i<<5 + bits.TrailingZeros32(w)
+				if pos >= bs.len {
+					break
+				}
+				bs.diff = append(bs.diff, pos)
+				w &= w - 1
+			}
+		}
+		return
+	}
+
+	for pos := 0; pos < bs.len; pos++ {
+		if (src[pos>>5]&(1<<(uint(pos)&31)) != 0) != bs.base.Has(pos) {
+			bs.diff = append(bs.diff, pos)
+		}
+	}
+}
+
+// Function dense returns a dense copy of the bit-string.
+func (bs *sparseBitSet) dense() *bitSet {
+	d := New(bs.len).(*bitSet)
+	for i := bs.NextSet(0); i >= 0; i = bs.NextSet(i + 1) {
+		d.Set(i)
+	}
+	return d
+}
+
+// Function load replaces the bit-string by the set bits of d and drops
+// the base, as the length may have changed.
+func (bs *sparseBitSet) load(d *bitSet) {
+	bs.len, bs.base, bs.diff = d.len, nil, bs.diff[:0]
+	for i := d.NextSet(0); i >= 0; i = d.NextSet(i + 1) {
+		bs.diff = append(bs.diff, i)
+	}
+}
+
+func (bs *sparseBitSet) String() string {
+	return bs.dense().String()
+}
+
+// The encodings are shared with the dense implementation, so both can
+// decode each other's data.
+
+func (bs *sparseBitSet) MarshalBinary() ([]byte, error) {
+	return bs.dense().MarshalBinary()
+}
+
+func (bs *sparseBitSet) UnmarshalBinary(data []byte) error {
+	d := new(bitSet)
+	if err := d.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	bs.load(d)
+	return nil
+}
+
+func (bs *sparseBitSet) MarshalText() ([]byte, error) {
+	return bs.dense().MarshalText()
+}
+
+func (bs *sparseBitSet) UnmarshalText(text []byte) error {
+	d := new(bitSet)
+	if err := d.UnmarshalText(text); err != nil {
+		return err
+	}
+	bs.load(d)
+	return nil
+}
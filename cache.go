@@ -0,0 +1,99 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+)
+
+// Compiled programs are cached as one file per cache key. The key covers
+// everything the compiled binary depends on, so changing the kernel
+// sources, build options, device or driver automatically invalidates the
+// cached binaries of the previous configuration.
+
+// Function kernelCacheDir returns the directory of cached program binaries
+// in the user cache directory.
+func kernelCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gom-opencl", "kernels"), nil
+}
+
+// Function kernelCacheKey returns the cache key of a program built from
+// the sources with the build options for the device and driver version.
+func kernelCacheKey(sources [][]byte, options, device, driver string) string {
+	h := sha256.New()
+
+	// Every field is prefixed by its length, so fields cannot run into
+	// each other.
+	write := func(data []byte) {
+		var length [8]byte
+		binary.LittleEndian.PutUint64(length[:], uint64(len(data)))
+		h.Write(length[:])
+		h.Write(data)
+	}
+
+	for _, source := range sources {
+		write(source)
+	}
+	write([]byte(options))
+	write([]byte(device))
+	write([]byte(driver))
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Function cachedBinaryPath returns the file of the cache key.
+func cachedBinaryPath(dir, key string) string {
+	return filepath.Join(dir, key+".bin")
+}
+
+// Function loadCachedBinary returns the cached binary of the key.
+func loadCachedBinary(dir, key string) ([]byte, error) {
+	return os.ReadFile(cachedBinaryPath(dir, key))
+}
+
+// Function storeCachedBinary caches the binary under the key. The binary
+// is written to a temporary file first, so concurrent runs never load a
+// partially written binary.
+func storeCachedBinary(dir, key string, data []byte) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), cachedBinaryPath(dir, key))
+	}
+	if err != nil {
+		os.Remove(file.Name())
+	}
+	return err
+}
+
+// Function removeCachedBinary invalidates the cached binary of the key,
+// e.g. because the driver rejected it.
+func removeCachedBinary(dir, key string) {
+	os.Remove(cachedBinaryPath(dir, key))
+}
+
+// Function clearKernelCache removes every cached binary.
+func clearKernelCache() error {
+	dir, err := kernelCacheDir()
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
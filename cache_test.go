@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestKernelCacheKey(t *testing.T) {
+	sources := [][]byte{[]byte("kernel a"), []byte("kernel b")}
+	key := kernelCacheKey(sources, "-D X", "device", "1.0")
+
+	if other := kernelCacheKey(sources, "-D X", "device", "1.0"); other != key {
+		t.Errorf("kernelCacheKey() = %s and %s for equal inputs.", key, other)
+	}
+
+	changes := []string{
+		kernelCacheKey([][]byte{[]byte("kernel a"), []byte("kernel c")}, "-D X", "device", "1.0"),
+		kernelCacheKey([][]byte{[]byte("kernel "), []byte("akernel b")}, "-D X", "device", "1.0"),
+		kernelCacheKey(sources, "-D Y", "device", "1.0"),
+		kernelCacheKey(sources, "-D X", "other device", "1.0"),
+		kernelCacheKey(sources, "-D X", "device", "1.1"),
+	}
+	for i, other := range changes {
+		if other == key {
+			t.Errorf("Change %d did not change the cache key.", i)
+		}
+	}
+}
+
+func TestCachedBinary(t *testing.T) {
+	dir := t.TempDir()
+	data := []byte{0, 1, 2, 3}
+
+	if _, err := loadCachedBinary(dir, "key"); err == nil {
+		t.Fatalf("loadCachedBinary() of an empty cache did not return an error.")
+	}
+
+	if err := storeCachedBinary(dir, "key", data); err != nil {
+		t.Fatalf("storeCachedBinary() returned error %q.", err)
+	}
+
+	if actual, err := loadCachedBinary(dir, "key"); err != nil || !bytes.Equal(actual, data) {
+		t.Errorf("loadCachedBinary() = %v, %v, expected %v.", actual, err, data)
+	}
+
+	removeCachedBinary(dir, "key")
+	if _, err := loadCachedBinary(dir, "key"); err == nil {
+		t.Errorf("loadCachedBinary() after removeCachedBinary() did not return an error.")
+	}
+}
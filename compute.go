@@ -0,0 +1,431 @@
+package main
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"unsafe"
+
+	"github.com/rainliu/gocl/cl"
+)
+
+// A computeDevice holds the OpenCL state of a single device performing GOM
+// on a slice of the population. Every device keeps a copy of the complete
+// population as the donor pool, but only mixes the solutions of its slice.
+type computeDevice struct {
+	device  cl.CL_device_id
+	context cl.CL_context
+	queue   cl.CL_command_queue
+	program cl.CL_program
+	kernel  cl.CL_kernel
+
+	population, clones, lt, improvs, offspring, fitness cl.CL_mem
+
+	// The slice of the population mixed by the device in a generation.
+	offset, count int
+
+	// The number of solutions mixed per second in the last generation.
+	throughput float64
+}
+
+// Function selectDevices returns the devices given by the -devices flag as
+// a comma-separated list of platform:device indices, or the single device
+// selected by findDevice if the flag is empty.
+func selectDevices(platforms []cl.CL_platform_id, deviceType cl.CL_device_type) []cl.CL_device_id {
+	if deviceList == "" {
+		_, device := findDevice(platforms, deviceType)
+		return []cl.CL_device_id{device}
+	}
+
+	var selected []cl.CL_device_id
+
+	for _, entry := range strings.Split(deviceList, ",") {
+		indices := strings.Split(strings.TrimSpace(entry), ":")
+		if len(indices) != 2 {
+			log.Fatalf("Fatal error: invalid device %q, expected platform:device.", entry)
+		}
+
+		p, err := strconv.Atoi(indices[0])
+		if err != nil || p < 0 || p >= len(platforms) {
+			log.Fatalf("Fatal error: invalid platform index in %q, see the devices command.", entry)
+		}
+
+		devices := getDevices(platforms[p], cl.CL_DEVICE_TYPE_ALL)
+
+		d, err := strconv.Atoi(indices[1])
+		if err != nil || d < 0 || d >= len(devices) {
+			log.Fatalf("Fatal error: invalid device index in %q, see the devices command.", entry)
+		}
+
+		for _, device := range selected {
+			if device == devices[d] {
+				log.Fatalf("Fatal error: device %q is selected more than once.", entry)
+			}
+		}
+
+		selected = append(selected, devices[d])
+	}
+
+	return selected
+}
+
+// Function newComputeDevice creates a context, command queue and GOM kernel
+// for the device, with buffers for a population of the given size and
+// solution length, split into the given number of islands.
+func newComputeDevice(device cl.CL_device_id, sources [][]byte, ft fitnessType, size, length, islands int) *computeDevice {
+	var status cl.CL_int
+
+	cd := &computeDevice{device: device}
+	devices := []cl.CL_device_id{device}
+
+	cd.context = cl.CLCreateContext(nil, 1, devices, nil, nil, &status)
+	requireSuccess(status, "could not create OpenCL context.")
+
+	cd.queue = cl.CLCreateCommandQueue(cd.context, device, 0, &status)
+	requireSuccess(status, "could not create OpenCL command queue.")
+
+	if ft.name == "double" && !strings.Contains(deviceInfoString(device, cl.CL_DEVICE_EXTENSIONS), "cl_khr_fp64") {
+		log.Fatalf("Fatal error: the device does not support double precision fitness values.")
+	}
+
+	cd.buildProgram(sources, ft.buildOptions(tolerance))
+
+	cd.kernel = cl.CLCreateKernel(cd.program, []byte("gom"), &status)
+	requireSuccess(status, "could not create OpenCL kernel.")
+
+	if verbosity >= 4 {
+		printDeviceInfo(device)
+		printKernelWorkGroup(cd.kernel, device)
+	}
+
+	dataSize := populationBytes(size, length)
+
+	// The population and offspring buffers swap roles every generation in
+	// resident mode, so both must be readable and writable by the kernel.
+	cd.population = cd.createBuffer(cl.CL_MEM_READ_WRITE, dataSize)
+	cd.clones = cd.createBuffer(cl.CL_MEM_READ_WRITE, dataSize)
+	cd.lt = cd.createBuffer(cl.CL_MEM_READ_ONLY, linkageTreeBytes(length, islands))
+	cd.improvs = cd.createBuffer(cl.CL_MEM_WRITE_ONLY, cl.CL_size_t(size))
+	cd.offspring = cd.createBuffer(cl.CL_MEM_READ_WRITE, dataSize)
+	cd.fitness = cd.createBuffer(cl.CL_MEM_WRITE_ONLY, cl.CL_size_t(ft.size*size))
+
+	return cd
+}
+
+// Function buildProgram builds the program of the device from its cached
+// binary if possible, or else from the sources, caching the binary.
+func (cd *computeDevice) buildProgram(sources [][]byte, options string) {
+	var status cl.CL_int
+
+	devices := []cl.CL_device_id{cd.device}
+
+	dir, err := kernelCacheDir()
+	if err != nil && !noKernelCache {
+		log.Printf("Warning: the kernel cache is disabled: %v", err)
+	}
+	cache := err == nil && !noKernelCache
+
+	key := kernelCacheKey(sources, options,
+		deviceInfoString(cd.device, cl.CL_DEVICE_NAME),
+		deviceInfoString(cd.device, cl.CL_DRIVER_VERSION))
+
+	if cache {
+		if data, err := loadCachedBinary(dir, key); err == nil {
+			lengths := []cl.CL_size_t{cl.CL_size_t(len(data))}
+			binaryStatus := make([]cl.CL_int, 1)
+
+			cd.program = cl.CLCreateProgramWithBinary(cd.context, 1, devices, lengths, [][]byte{data}, binaryStatus, &status)
+
+			if status == cl.CL_SUCCESS && binaryStatus[0] == cl.CL_SUCCESS &&
+				cl.CLBuildProgram(cd.program, 1, devices, []byte(options), nil, nil) == cl.CL_SUCCESS {
+				if verbosity >= 4 {
+					log.Printf("Loaded the cached program binary %s.", key)
+				}
+				return
+			}
+
+			// The driver rejected the binary, so it is rebuilt from source.
+			if status == cl.CL_SUCCESS {
+				cl.CLReleaseProgram(cd.program)
+			}
+			removeCachedBinary(dir, key)
+		}
+	}
+
+	lengths := make([]cl.CL_size_t, len(sources))
+	for i, source := range sources {
+		lengths[i] = cl.CL_size_t(len(source))
+	}
+
+	cd.program = cl.CLCreateProgramWithSource(cd.context, cl.CL_uint(len(sources)), sources, lengths, &status)
+	requireSuccess(status, "could not compile an OpenCL kernel from source.")
+
+	status = cl.CLBuildProgram(cd.program, 1, devices, []byte(options), nil, nil)
+
+	if status != cl.CL_SUCCESS {
+		printProgramBuildInfo(cd.program, cd.device)
+	}
+
+	if cache {
+		if err := storeCachedBinary(dir, key, programBinary(cd.program)); err != nil {
+			log.Printf("Warning: could not cache the program binary: %v", err)
+		}
+	}
+}
+
+// Function programBinary returns the compiled binary of a program built
+// for a single device.
+func programBinary(program cl.CL_program) []byte {
+	var size cl.CL_size_t
+	var info interface{}
+
+	requireSuccess(cl.CLGetProgramInfo(program, cl.CL_PROGRAM_BINARY_SIZES,
+		cl.CL_size_t(unsafe.Sizeof(size)), &info, nil),
+		"could not retrieve the OpenCL program binary size.")
+
+	size = info.([]cl.CL_size_t)[0]
+
+	// The binary is written into the buffer provided for the device.
+	info = [][]byte{make([]byte, size)}
+
+	requireSuccess(cl.CLGetProgramInfo(program, cl.CL_PROGRAM_BINARIES,
+		cl.CL_size_t(unsafe.Sizeof(uintptr(0))), &info, nil),
+		"could not retrieve the OpenCL program binary.")
+
+	return info.([][]byte)[0]
+}
+
+// Function populationBytes returns the size in bytes of a population of
+// bit-strings packed in 32-bit words.
+func populationBytes(size, length int) cl.CL_size_t {
+	return cl.CL_size_t(4 * size * (((length - 1) >> 5) + 1))
+}
+
+// Function linkageTreeBytes returns the maximum size in bytes of the
+// flattened linkage trees of the islands over the given number of
+// variables, including the offset of every tree.
+func linkageTreeBytes(length, islands int) cl.CL_size_t {
+	l := cl.CL_size_t(length)
+
+	// Maximum bound on the number of elements in the LT + node sizes.
+	return 4 * cl.CL_size_t(islands) * ((l*l+3*l-2)/2 + (2*l - 1) + 2)
+}
+
+func (cd *computeDevice) createBuffer(flags cl.CL_mem_flags, size cl.CL_size_t) cl.CL_mem {
+	var status cl.CL_int
+
+	buffer := cl.CLCreateBuffer(cd.context, flags, size, nil, &status)
+	requireSuccess(status, "could not allocate an OpenCL memory buffer.")
+
+	return buffer
+}
+
+// Function write uploads data to the start of a buffer of the device.
+func (cd *computeDevice) write(buffer cl.CL_mem, size cl.CL_size_t, data unsafe.Pointer) {
+	requireSuccess(cl.CLEnqueueWriteBuffer(
+		cd.queue, buffer, cl.CL_TRUE, 0,
+		size, data, 0, nil, nil),
+		"could not write data to an OpenCL memory buffer.")
+}
+
+// Function read downloads size bytes at the offset of a buffer of the
+// device.
+func (cd *computeDevice) read(buffer cl.CL_mem, offset, size cl.CL_size_t, data unsafe.Pointer) {
+	requireSuccess(cl.CLEnqueueReadBuffer(
+		cd.queue, buffer, cl.CL_TRUE, offset,
+		size, data, 0, nil, nil),
+		"reading a buffer failed.")
+}
+
+// Function mix performs GOM on the slice of the device and reads back the
+// offspring, improvements and fitness values of the slice into the host
+// arrays, which hold the complete population. The throughput of the device
+// is updated with the time taken.
+func (cd *computeDevice) mix(size, length, islandSize int, ft fitnessType, offspring []uint32, improvs []cl.CL_char, fitness []byte) {
+	if cd.count == 0 {
+		return
+	}
+
+	start := time.Now()
+
+	popSize := cl.CL_uint(size)
+	solLength := cl.CL_uint(length)
+	islandLength := cl.CL_uint(islandSize)
+	setKernelArg(cd.kernel, 0, &cd.population)
+	setKernelArg(cd.kernel, 1, &popSize)
+	setKernelArg(cd.kernel, 2, &solLength)
+	setKernelArg(cd.kernel, 3, &cd.clones)
+	setKernelArg(cd.kernel, 4, &cd.lt)
+	setKernelArg(cd.kernel, 5, &cd.improvs)
+	setKernelArg(cd.kernel, 6, &cd.offspring)
+	setKernelArg(cd.kernel, 7, &cd.fitness)
+	setKernelArg(cd.kernel, 8, &islandLength)
+
+	// The global work offset makes the global ids of the work items equal
+	// to the indices of the solutions in the complete population.
+	globalWorkOffset := []cl.CL_size_t{cl.CL_size_t(cd.offset)}
+	globalWorkSize := []cl.CL_size_t{cl.CL_size_t(cd.count)}
+
+	requireSuccess(cl.CLEnqueueNDRangeKernel(
+		cd.queue, cd.kernel, 1, globalWorkOffset, globalWorkSize,
+		nil, 0, nil, nil),
+		"could not enqueue OpenCL kernel.")
+
+	requireSuccess(cl.CLFinish(cd.queue), "could not finish command queue.")
+
+	blocks := ((length - 1) >> 5) + 1
+	first, n := cd.offset*blocks, cd.count*blocks
+
+	cd.read(cd.offspring, cl.CL_size_t(4*first), cl.CL_size_t(4*n), unsafe.Pointer(&offspring[first]))
+	cd.read(cd.improvs, cl.CL_size_t(cd.offset), cl.CL_size_t(cd.count), unsafe.Pointer(&improvs[cd.offset]))
+	cd.read(cd.fitness, cl.CL_size_t(ft.size*cd.offset), cl.CL_size_t(ft.size*cd.count),
+		unsafe.Pointer(&fitness[ft.size*cd.offset]))
+
+	cd.throughput = float64(cd.count) / time.Since(start).Seconds()
+}
+
+// Function evaluate evaluates the solutions in data, packed as by
+// PutUInt32s, on the device and returns their fitness values.
+func (cd *computeDevice) evaluate(data []uint32, size, length int, ft fitnessType) []float64 {
+	var status cl.CL_int
+
+	kernel := cl.CLCreateKernel(cd.program, []byte("evaluate_all"), &status)
+	requireSuccess(status, "could not create OpenCL kernel.")
+	defer cl.CLReleaseKernel(kernel)
+
+	solutions := cd.createBuffer(cl.CL_MEM_READ_ONLY, populationBytes(size, length))
+	defer cl.CLReleaseMemObject(solutions)
+
+	fitness := cd.createBuffer(cl.CL_MEM_WRITE_ONLY, cl.CL_size_t(ft.size*size))
+	defer cl.CLReleaseMemObject(fitness)
+
+	cd.write(solutions, populationBytes(size, length), unsafe.Pointer(&data[0]))
+
+	solLength := cl.CL_uint(length)
+	setKernelArg(kernel, 0, &solutions)
+	setKernelArg(kernel, 1, &solLength)
+	setKernelArg(kernel, 2, &fitness)
+
+	globalWorkSize := []cl.CL_size_t{cl.CL_size_t(size)}
+
+	requireSuccess(cl.CLEnqueueNDRangeKernel(
+		cd.queue, kernel, 1, nil, globalWorkSize,
+		nil, 0, nil, nil),
+		"could not enqueue OpenCL kernel.")
+
+	requireSuccess(cl.CLFinish(cd.queue), "could not finish command queue.")
+
+	fitnessData := make([]byte, ft.size*size)
+	cd.read(fitness, 0, cl.CL_size_t(len(fitnessData)), unsafe.Pointer(&fitnessData[0]))
+
+	values := make([]float64, size)
+	ft.decodeAll(fitnessData, values)
+	return values
+}
+
+// Function release frees the OpenCL objects of the device.
+func (cd *computeDevice) release() {
+	for _, buffer := range []cl.CL_mem{cd.population, cd.clones, cd.lt, cd.improvs, cd.offspring, cd.fitness} {
+		cl.CLReleaseMemObject(buffer)
+	}
+	cl.CLReleaseKernel(cd.kernel)
+	cl.CLReleaseProgram(cd.program)
+	cl.CLReleaseCommandQueue(cd.queue)
+	cl.CLReleaseContext(cd.context)
+}
+
+// A realDevice evaluates real-valued solutions with the evaluate_real
+// kernel in double precision, holding buffers for a population of a fixed
+// size and length and the data of the problem.
+type realDevice struct {
+	computeDevice
+
+	size, length int
+	data         cl.CL_mem
+}
+
+// Function newRealDevice creates a context, command queue and the
+// evaluate_real kernel for the device, with the problem data uploaded.
+func newRealDevice(device cl.CL_device_id, sources [][]byte, size, length int, data []float64) *realDevice {
+	var status cl.CL_int
+
+	rd := &realDevice{computeDevice: computeDevice{device: device}, size: size, length: length}
+	devices := []cl.CL_device_id{device}
+
+	rd.context = cl.CLCreateContext(nil, 1, devices, nil, nil, &status)
+	requireSuccess(status, "could not create OpenCL context.")
+
+	rd.queue = cl.CLCreateCommandQueue(rd.context, device, 0, &status)
+	requireSuccess(status, "could not create OpenCL command queue.")
+
+	if !strings.Contains(deviceInfoString(device, cl.CL_DEVICE_EXTENSIONS), "cl_khr_fp64") {
+		log.Fatalf("Fatal error: real-valued problems require a device with double precision.")
+	}
+
+	rd.buildProgram(sources, findFitnessType("double").buildOptions(0))
+
+	rd.kernel = cl.CLCreateKernel(rd.program, []byte("evaluate_real"), &status)
+	requireSuccess(status, "could not create OpenCL kernel.")
+
+	if verbosity >= 4 {
+		printDeviceInfo(device)
+		printKernelWorkGroup(rd.kernel, device)
+	}
+
+	// The problem data buffer is never empty, so it can always be bound.
+	if len(data) == 0 {
+		data = []float64{0}
+	}
+
+	rd.population = rd.createBuffer(cl.CL_MEM_READ_ONLY, cl.CL_size_t(8*size*length))
+	rd.fitness = rd.createBuffer(cl.CL_MEM_WRITE_ONLY, cl.CL_size_t(8*size))
+	rd.data = rd.createBuffer(cl.CL_MEM_READ_ONLY, cl.CL_size_t(8*len(data)))
+	rd.write(rd.data, cl.CL_size_t(8*len(data)), unsafe.Pointer(&data[0]))
+
+	return rd
+}
+
+// EvaluateReal evaluates the objective values of at most the population
+// size of solutions on the device.
+func (rd *realDevice) EvaluateReal(xs [][]float64) []float64 {
+	if len(xs) == 0 {
+		return nil
+	}
+
+	values := make([]float64, len(xs)*rd.length)
+	for i, x := range xs {
+		copy(values[i*rd.length:], x)
+	}
+	rd.write(rd.population, cl.CL_size_t(8*len(values)), unsafe.Pointer(&values[0]))
+
+	length := cl.CL_uint(rd.length)
+	setKernelArg(rd.kernel, 0, &rd.population)
+	setKernelArg(rd.kernel, 1, &length)
+	setKernelArg(rd.kernel, 2, &rd.data)
+	setKernelArg(rd.kernel, 3, &rd.fitness)
+
+	globalWorkSize := []cl.CL_size_t{cl.CL_size_t(len(xs))}
+
+	requireSuccess(cl.CLEnqueueNDRangeKernel(
+		rd.queue, rd.kernel, 1, nil, globalWorkSize,
+		nil, 0, nil, nil),
+		"could not enqueue OpenCL kernel.")
+
+	requireSuccess(cl.CLFinish(rd.queue), "could not finish command queue.")
+
+	objectives := make([]float64, len(xs))
+	rd.read(rd.fitness, 0, cl.CL_size_t(8*len(xs)), unsafe.Pointer(&objectives[0]))
+	return objectives
+}
+
+// Function release frees the OpenCL objects of the device.
+func (rd *realDevice) release() {
+	for _, buffer := range []cl.CL_mem{rd.population, rd.fitness, rd.data} {
+		cl.CLReleaseMemObject(buffer)
+	}
+	cl.CLReleaseKernel(rd.kernel)
+	cl.CLReleaseProgram(rd.program)
+	cl.CLReleaseCommandQueue(rd.queue)
+	cl.CLReleaseContext(rd.context)
+}
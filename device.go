@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/rainliu/gocl/cl"
+)
+
+// Function getPlatforms returns all OpenCL platforms.
+func getPlatforms() []cl.CL_platform_id {
+	var numPlatforms cl.CL_uint
+
+	requireSuccess(cl.CLGetPlatformIDs(0, nil, &numPlatforms),
+		"could not retrieve the number of OpenCL platforms.")
+
+	platforms := make([]cl.CL_platform_id, numPlatforms)
+
+	if numPlatforms > 0 {
+		requireSuccess(cl.CLGetPlatformIDs(numPlatforms, platforms, nil),
+			"could not retrieve OpenCL platform IDs.")
+	}
+
+	return platforms
+}
+
+// Function getDevices returns the devices of the given type on the
+// platform, or nil if the platform has no such devices.
+func getDevices(platform cl.CL_platform_id, deviceType cl.CL_device_type) []cl.CL_device_id {
+	var numDevices cl.CL_uint
+
+	// Get the number of matching devices for the platform.
+	status := cl.CLGetDeviceIDs(platform, deviceType, 0, nil, &numDevices)
+
+	switch status {
+	case cl.CL_DEVICE_NOT_FOUND:
+		return nil
+	case cl.CL_SUCCESS:
+		if numDevices == 0 {
+			return nil
+		}
+	default:
+		log.Printf("OpenCL failed with status code: %s", cl.ERROR_CODES_STRINGS[-status])
+		log.Fatalf("Fatal error: could not retrieve devices for platform %d", platform)
+	}
+
+	devices := make([]cl.CL_device_id, numDevices)
+
+	requireSuccess(cl.CLGetDeviceIDs(platform, deviceType, numDevices, devices, nil),
+		"could not retrieve OpenCL device IDs.")
+
+	return devices
+}
+
+// Function findDevice selects a device using the -platform, -device and
+// -device-name flags. Platform and device indices are those printed by the
+// devices command. Unless a device index is given, only devices of the
+// preferred type are considered.
+func findDevice(platforms []cl.CL_platform_id, deviceType cl.CL_device_type) (platformID cl.CL_platform_id, deviceID cl.CL_device_id) {
+
+	for p, platform := range platforms {
+		if platformIndex >= 0 && p != platformIndex {
+			continue
+		}
+
+		for d, device := range getDevices(platform, cl.CL_DEVICE_TYPE_ALL) {
+			if deviceIndex >= 0 && d != deviceIndex {
+				continue
+			}
+
+			if deviceIndex < 0 && deviceInfo(device, cl.CL_DEVICE_TYPE).(cl.CL_device_type)&deviceType == 0 {
+				continue
+			}
+
+			name := deviceInfoString(device, cl.CL_DEVICE_NAME)
+			if deviceName != "" && !strings.Contains(strings.ToLower(name), strings.ToLower(deviceName)) {
+				continue
+			}
+
+			return platform, device
+		}
+	}
+
+	log.Fatalf("Fatal error: no OpenCL device matches the selection, see the devices command.")
+	return
+}
+
+// Function deviceInfo returns a property of the device.
+func deviceInfo(device cl.CL_device_id, name cl.CL_device_info) interface{} {
+	var size cl.CL_size_t
+	var info interface{}
+
+	requireSuccess(cl.CLGetDeviceInfo(device, name, 0, nil, &size),
+		"could not retrieve OpenCL device info.")
+	requireSuccess(cl.CLGetDeviceInfo(device, name, size, &info, nil),
+		"could not retrieve OpenCL device info.")
+
+	return info
+}
+
+// Function deviceInfoString returns a string property of the device.
+func deviceInfoString(device cl.CL_device_id, name cl.CL_device_info) string {
+	return deviceInfo(device, name).(string)
+}
+
+// Function platformInfoString returns a string property of the platform.
+func platformInfoString(platform cl.CL_platform_id, name cl.CL_platform_info) string {
+	var size cl.CL_size_t
+	var info interface{}
+
+	requireSuccess(cl.CLGetPlatformInfo(platform, name, 0, nil, &size),
+		"could not retrieve OpenCL platform info.")
+	requireSuccess(cl.CLGetPlatformInfo(platform, name, size, &info, nil),
+		"could not retrieve OpenCL platform info.")
+
+	return info.(string)
+}
+
+// Function deviceTypeName returns a readable name of the device type.
+func deviceTypeName(deviceType cl.CL_device_type) string {
+	var names []string
+
+	if deviceType&cl.CL_DEVICE_TYPE_CPU != 0 {
+		names = append(names, "CPU")
+	}
+	if deviceType&cl.CL_DEVICE_TYPE_GPU != 0 {
+		names = append(names, "GPU")
+	}
+	if deviceType&cl.CL_DEVICE_TYPE_ACCELERATOR != 0 {
+		names = append(names, "Accelerator")
+	}
+	if len(names) == 0 {
+		return "Other"
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// Function printDevices lists every platform and device with their
+// indices for the -platform and -device flags.
+func printDevices() {
+	platforms := getPlatforms()
+
+	if len(platforms) == 0 {
+		fmt.Println("No OpenCL platforms found.")
+		return
+	}
+
+	for p, platform := range platforms {
+		fmt.Printf("Platform %d: %s\n", p, platformInfoString(platform, cl.CL_PLATFORM_NAME))
+		fmt.Printf("  %-22s: %s\n", "Vendor", platformInfoString(platform, cl.CL_PLATFORM_VENDOR))
+		fmt.Printf("  %-22s: %s\n", "Version", platformInfoString(platform, cl.CL_PLATFORM_VERSION))
+
+		for d, device := range getDevices(platform, cl.CL_DEVICE_TYPE_ALL) {
+			extensions := deviceInfoString(device, cl.CL_DEVICE_EXTENSIONS)
+
+			fmt.Printf("  Device %d: %s\n", d, deviceInfoString(device, cl.CL_DEVICE_NAME))
+			fmt.Printf("    %-20s: %s\n", "Type", deviceTypeName(deviceInfo(device, cl.CL_DEVICE_TYPE).(cl.CL_device_type)))
+			fmt.Printf("    %-20s: %s\n", "Vendor", deviceInfoString(device, cl.CL_DEVICE_VENDOR))
+			fmt.Printf("    %-20s: %s\n", "Version", deviceInfoString(device, cl.CL_DEVICE_VERSION))
+			fmt.Printf("    %-20s: %s\n", "Driver Version", deviceInfoString(device, cl.CL_DRIVER_VERSION))
+			fmt.Printf("    %-20s: %v\n", "Max Compute Units", deviceInfo(device, cl.CL_DEVICE_MAX_COMPUTE_UNITS))
+			fmt.Printf("    %-20s: %v MHz\n", "Max Clock Frequency", deviceInfo(device, cl.CL_DEVICE_MAX_CLOCK_FREQUENCY))
+			fmt.Printf("    %-20s: %v\n", "Max Work Group Size", deviceInfo(device, cl.CL_DEVICE_MAX_WORK_GROUP_SIZE))
+			fmt.Printf("    %-20s: %v\n", "Global Mem Size", deviceInfo(device, cl.CL_DEVICE_GLOBAL_MEM_SIZE))
+			fmt.Printf("    %-20s: %v\n", "Local Mem Size", deviceInfo(device, cl.CL_DEVICE_LOCAL_MEM_SIZE))
+			fmt.Printf("    %-20s: %t\n", "Double Precision", strings.Contains(extensions, "cl_khr_fp64"))
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/Morenim/gom-opencl/bitset"
+	"github.com/Morenim/gom-opencl/ga"
+	"github.com/Morenim/gom-opencl/problem"
+)
+
+// ECGA is the extended compact genetic algorithm of Harik. Every
+// generation it learns a marginal product model of the selected solutions
+// by minimum description length, and samples a new population from it.
+// All randomness is drawn from Rand.
+type ECGA struct {
+	Problem    problem.Problem
+	Population *ga.Population
+	Rand       *rand.Rand
+
+	// The selected solutions are the best of TournamentSize solutions
+	// drawn with replacement, compared by constraint domination up to
+	// Tolerance.
+	TournamentSize int
+	Tolerance      float64
+
+	// The marginal product model learned in the last generation, a
+	// partition of the bits into groups of sorted indices.
+	Model [][]int
+
+	// Whether the solutions of the population are optimal, their
+	// constraint violations, and the best solution found so far.
+	optimal   []bool
+	violation []float64
+	elite     elite
+}
+
+// NewECGA returns an ECGA with tournaments of size 4, evaluating every
+// solution in the population.
+func NewECGA(p problem.Problem, pop *ga.Population, r *rand.Rand) *ECGA {
+	e := &ECGA{Problem: p, Population: pop, Rand: r, TournamentSize: 4}
+	e.optimal, e.violation = evaluatePopulation(p, pop)
+	e.elite.update(pop, e.violation, e.Tolerance)
+	return e
+}
+
+// Optimal tests whether the population contains an optimal solution.
+func (e *ECGA) Optimal() bool {
+	return anyOptimal(e.optimal)
+}
+
+// Step performs a single generation: it selects as many solutions as the
+// population holds by tournament selection, learns the marginal product
+// model of the selection and replaces the population by samples of the
+// model. Step reports whether the best solution found so far improved and
+// whether an optimal solution was found.
+func (e *ECGA) Step() (improved, optimal bool) {
+	pop := e.Population
+
+	// The selected solutions share the bits of the population, which are
+	// only read.
+	selected := &ga.Population{Solutions: make([]ga.Solution, pop.Size())}
+	for i := range selected.Solutions {
+		selected.Solutions[i] = pop.Solutions[tournament(pop, e.violation, e.TournamentSize, e.Tolerance, e.Rand)]
+	}
+
+	e.Model = marginalProductModel(selected)
+
+	// Copying the bits of every group from a random selected solution
+	// samples the marginal distribution of the group.
+	offspring := &ga.Population{Solutions: make([]ga.Solution, pop.Size())}
+	for i := range offspring.Solutions {
+		bits := bitset.New(pop.Length())
+		for _, group := range e.Model {
+			bits.CopyBits(selected.Solutions[e.Rand.Intn(selected.Size())].Bits, group)
+		}
+		offspring.Solutions[i].Bits = bits
+	}
+
+	pop.Solutions = offspring.Solutions
+	e.optimal, e.violation = evaluatePopulation(e.Problem, pop)
+
+	return e.elite.update(pop, e.violation, e.Tolerance), e.Optimal()
+}
+
+// Function marginalProductModel partitions the bits of the population into
+// groups by greedily merging the pair of groups that lowers the combined
+// complexity the most, starting from single bits, until no merge lowers it.
+func marginalProductModel(pop *ga.Population) [][]int {
+	model := make([][]int, pop.Length())
+	complexity := make([]float64, len(model))
+	for i := range model {
+		model[i] = []int{i}
+		complexity[i] = groupComplexity(pop, model[i])
+	}
+
+	// Merged holds the combined complexity of every pair of groups after
+	// merging them, which is only recomputed for a group that changed.
+	merged := newMatrix(len(model))
+	for i := range model {
+		for j := 0; j < i; j++ {
+			merged.set(i, j, mergedComplexity(pop, model[i], model[j]))
+		}
+	}
+
+	for len(model) > 1 {
+		a, b, gain := 0, 0, 0.0
+		for i := range model {
+			for j := 0; j < i; j++ {
+				if g := complexity[i] + complexity[j] - merged.get(i, j); g > gain {
+					a, b, gain = i, j, g
+				}
+			}
+		}
+		if gain <= 0 {
+			break
+		}
+
+		// Group b is replaced by the merged group, and group a by the
+		// last group.
+		model[b] = mergeClusters(model[a], model[b])
+		complexity[b] = merged.get(a, b)
+
+		last := len(model) - 1
+		model[a], complexity[a] = model[last], complexity[last]
+		for i := 0; i < last; i++ {
+			if i != a {
+				merged.set(a, i, merged.get(last, i))
+			}
+		}
+		model, complexity = model[:last], complexity[:last]
+
+		for i := range model {
+			if i != b {
+				merged.set(b, i, mergedComplexity(pop, model[b], model[i]))
+			}
+		}
+	}
+
+	return model
+}
+
+// Function groupComplexity returns the combined complexity of a group of
+// bits, measured in bits: the model complexity of its marginal
+// distribution, whose 2^|group|-1 probabilities take log2(N+1) bits each,
+// plus the compressed population complexity, N times the entropy of the
+// group.
+func groupComplexity(pop *ga.Population, group []int) float64 {
+	n := float64(pop.Size())
+	model := math.Log2(n+1) * float64(int(1)<<uint(len(group))-1)
+	return model + n*entropy(frequency(pop, group), pop.Size())/math.Ln2
+}
+
+// Function mergedComplexity returns the combined complexity of the union of
+// two groups. The entropy of the union is at least that of either group, so
+// the merge cannot pay off if the added model complexity exceeds N times
+// the smaller group length, and the frequencies are then not counted.
+func mergedComplexity(pop *ga.Population, a, b []int) float64 {
+	n := float64(pop.Size())
+	added := math.Log2(n+1) * float64(int(1)<<uint(len(a)+len(b))-int(1)<<uint(len(a))-int(1)<<uint(len(b))+1)
+	shorter := len(a)
+	if len(b) < shorter {
+		shorter = len(b)
+	}
+	if added >= n*float64(shorter) {
+		return math.Inf(1)
+	}
+	return groupComplexity(pop, mergeClusters(a, b))
+}
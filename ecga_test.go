@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"github.com/Morenim/gom-opencl/ga"
+	"github.com/Morenim/gom-opencl/problem"
+	"math/rand"
+	"testing"
+)
+
+func TestMarginalProductModelFindsBlocks(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	// Every block of 4 bits is all zeros or all ones, independently of the
+	// other blocks.
+	pop := ga.NewPopulation(200, 16, r)
+	for _, solution := range pop.Solutions {
+		solution.Bits.ClearRange(0, 16)
+		for block := 0; block < 4; block++ {
+			if r.Intn(2) == 0 {
+				solution.Bits.SetRange(4*block, 4*block+4)
+			}
+		}
+	}
+
+	model := marginalProductModel(pop)
+
+	groups := make(map[string]bool)
+	for _, group := range model {
+		groups[fmt.Sprint(group)] = true
+	}
+	for _, block := range problem.DeceptiveTrap(4).Blocks(16) {
+		if !groups[fmt.Sprint(block)] {
+			t.Errorf("marginalProductModel() = %v, expected block %v.", model, block)
+		}
+	}
+}
+
+func TestMarginalProductModelIndependent(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	pop := ga.NewPopulation(500, 12, r)
+
+	if model := marginalProductModel(pop); len(model) != 12 {
+		t.Errorf("marginalProductModel() = %v for independent bits, expected single bits.", model)
+	}
+}
+
+func TestECGASolvesTrap(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	pop := ga.NewPopulation(500, 20, r)
+	e := NewECGA(problem.DeceptiveTrap(4), pop, r)
+
+	for generation := 0; generation < 50 && !e.Optimal(); generation++ {
+		e.Step()
+	}
+
+	if !e.Optimal() {
+		t.Errorf("ECGA did not solve the deceptive trap, best fitness %v, model %v.", pop.BestFitness(), e.Model)
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Function nodeLabel returns a compact description of the problem
+// variables in a FOS subset, e.g. "0 1 2 3".
+func nodeLabel(subset []int) string {
+	labels := make([]string, len(subset))
+	for i, v := range subset {
+		labels[i] = strconv.Itoa(v)
+	}
+	return strings.Join(labels, " ")
+}
+
+// Function writeDOT writes the linkage tree as a Graphviz digraph. Every
+// merged node is labelled with the order of its merge and the mutual
+// information between its two children at the time of merging.
+func writeDOT(w io.Writer, fos [][]int, merges []Merge) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, "digraph linkage {")
+	fmt.Fprintln(bw, "  node [shape=box];")
+
+	merged := make(map[int]int, len(merges))
+	for i, m := range merges {
+		merged[m.Node] = i
+	}
+
+	for i, subset := range fos {
+		if order, ok := merged[i]; ok {
+			fmt.Fprintf(bw, "  n%d [label=\"{%s}\\nmerge %d\\nMI = %.5f\"];\n",
+				i, nodeLabel(subset), order, merges[order].Similarity)
+		} else {
+			fmt.Fprintf(bw, "  n%d [label=\"{%s}\"];\n", i, nodeLabel(subset))
+		}
+	}
+
+	for _, m := range merges {
+		fmt.Fprintf(bw, "  n%d -> n%d;\n", m.Node, m.Left)
+		fmt.Fprintf(bw, "  n%d -> n%d;\n", m.Node, m.Right)
+	}
+
+	fmt.Fprintln(bw, "}")
+
+	return bw.Flush()
+}
+
+// Function writeNewick writes the linkage tree in Newick notation. Leaves
+// are named after their problem variable and internal nodes carry the
+// merge order and similarity as an extended Newick comment.
+func writeNewick(w io.Writer, fos [][]int, merges []Merge) error {
+	if len(fos) == 0 {
+		_, err := io.WriteString(w, ";\n")
+		return err
+	}
+
+	children := make(map[int]Merge, len(merges))
+	order := make(map[int]int, len(merges))
+	for i, m := range merges {
+		children[m.Node] = m
+		order[m.Node] = i
+	}
+
+	var buffer strings.Builder
+
+	var visit func(node int)
+	visit = func(node int) {
+		m, ok := children[node]
+		if !ok {
+			buffer.WriteString(nodeLabel(fos[node]))
+			return
+		}
+		buffer.WriteString("(")
+		visit(m.Left)
+		buffer.WriteString(",")
+		visit(m.Right)
+		fmt.Fprintf(&buffer, ")[&merge=%d,similarity=%.5f]", order[node], m.Similarity)
+	}
+
+	// The root is the last subset created, or the only singleton.
+	visit(len(fos) - 1)
+	buffer.WriteString(";\n")
+
+	_, err := io.WriteString(w, buffer.String())
+	return err
+}
+
+// Function writeMatrixCSV writes the full symmetric matrix as
+// comma-separated values, one row per problem variable.
+func writeMatrixCSV(w io.Writer, m *matrix) error {
+	bw := bufio.NewWriter(w)
+
+	for i := 0; i < len(m.data); i++ {
+		for j := 0; j < len(m.data); j++ {
+			if j > 0 {
+				bw.WriteString(",")
+			}
+			bw.WriteString(strconv.FormatFloat(m.get(i, j), 'g', -1, 64))
+		}
+		bw.WriteString("\n")
+	}
+
+	return bw.Flush()
+}
+
+// Function dumpLinkage writes the linkage tree and the distance matrix of
+// an island in the given generation to files named after the dump prefix,
+// and after the island if there are several.
+func dumpLinkage(generation, island int, fos [][]int, merges []Merge, distances *matrix) {
+
+	prefix := dumpPrefix
+	if numIslands > 1 {
+		prefix = fmt.Sprintf("%s_island%d", dumpPrefix, island)
+	}
+
+	write := func(suffix string, writer func(io.Writer) error) {
+		name := fmt.Sprintf("%s_gen%04d.%s", prefix, generation, suffix)
+
+		file, err := os.Create(name)
+		if err != nil {
+			log.Fatalf("Fatal error: could not create dump file %s: %v", name, err)
+		}
+		defer file.Close()
+
+		if err := writer(file); err != nil {
+			log.Fatalf("Fatal error: could not write dump file %s: %v", name, err)
+		}
+	}
+
+	switch dumpFormat {
+	case "dot":
+		write("dot", func(w io.Writer) error { return writeDOT(w, fos, merges) })
+	case "newick":
+		write("nwk", func(w io.Writer) error { return writeNewick(w, fos, merges) })
+	default:
+		log.Fatalf("Fatal error: unknown linkage tree format %q.", dumpFormat)
+	}
+
+	write("csv", func(w io.Writer) error { return writeMatrixCSV(w, distances) })
+}
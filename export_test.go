@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+// The linkage tree of three variables merging 0 and 1 first.
+var (
+	exportFOS    = [][]int{{0}, {1}, {2}, {0, 1}, {0, 1, 2}}
+	exportMerges = []Merge{{0, 1, 3, 0.5}, {3, 2, 4, 0.25}}
+)
+
+var exportTests = []struct {
+	name     string
+	write    func(w io.Writer) error
+	expected string
+}{
+	{"writeDOT", func(w io.Writer) error { return writeDOT(w, exportFOS, exportMerges) }, `digraph linkage {
+  node [shape=box];
+  n0 [label="{0}"];
+  n1 [label="{1}"];
+  n2 [label="{2}"];
+  n3 [label="{0 1}\nmerge 0\nMI = 0.50000"];
+  n4 [label="{0 1 2}\nmerge 1\nMI = 0.25000"];
+  n3 -> n0;
+  n3 -> n1;
+  n4 -> n3;
+  n4 -> n2;
+}
+`},
+	{"writeNewick", func(w io.Writer) error { return writeNewick(w, exportFOS, exportMerges) },
+		"((0,1)[&merge=0,similarity=0.50000],2)[&merge=1,similarity=0.25000];\n"},
+	{"writeNewick of a singleton", func(w io.Writer) error { return writeNewick(w, [][]int{{0}}, nil) }, "0;\n"},
+	{"writeNewick of an empty tree", func(w io.Writer) error { return writeNewick(w, nil, nil) }, ";\n"},
+	{"writeMatrixCSV", func(w io.Writer) error {
+		m := newMatrix(2)
+		m.set(1, 0, 0.5)
+		m.set(1, 1, 1)
+		return writeMatrixCSV(w, m)
+	}, "0,0.5\n0.5,1\n"},
+}
+
+func TestExport(t *testing.T) {
+	for _, test := range exportTests {
+		var buffer bytes.Buffer
+		if err := test.write(&buffer); err != nil {
+			t.Errorf("%s returned error %q.", test.name, err)
+		} else if actual := buffer.String(); actual != test.expected {
+			t.Errorf("%s wrote %q, expected %q.", test.name, actual, test.expected)
+		}
+	}
+}
+
+func TestLinkageTreeMerges(t *testing.T) {
+	freqs := Frequencies(&deceptivePopulation)
+	fos, merges := LinkageTreeMerges(&deceptivePopulation, freqs, rand.New(rand.NewSource(1)))
+
+	length := deceptivePopulation.Length()
+	if len(merges) != length-1 {
+		t.Fatalf("LinkageTreeMerges() recorded %d merges of %d variables, expected %d.", len(merges), length, length-1)
+	}
+
+	// Every merge creates the next subset from two earlier subsets, each of
+	// which is merged only once.
+	used := make(map[int]bool)
+	for i, m := range merges {
+		if m.Node != length+i || m.Left >= m.Node || m.Right >= m.Node || used[m.Left] || used[m.Right] {
+			t.Fatalf("Merge %d = %+v is out of order.", i, m)
+		}
+		used[m.Left], used[m.Right] = true, true
+
+		expected := mergeClusters(fos[m.Left], fos[m.Right])
+		if fmt.Sprint(fos[m.Node]) != fmt.Sprint(expected) {
+			t.Errorf("Merge %d created %v, expected %v.", i, fos[m.Node], expected)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+)
+
+// A fitnessType describes a type of fitness values returned by evaluate()
+// on the compute device, selected at build time with FITNESS_TYPE.
+type fitnessType struct {
+	name    string
+	size    int
+	integer bool
+	decode  func(data []byte) float64
+}
+
+var fitnessTypes = []fitnessType{
+	{"uint", 4, true, func(data []byte) float64 {
+		return float64(binary.NativeEndian.Uint32(data))
+	}},
+	{"int", 4, true, func(data []byte) float64 {
+		return float64(int32(binary.NativeEndian.Uint32(data)))
+	}},
+	{"float", 4, false, func(data []byte) float64 {
+		return float64(math.Float32frombits(binary.NativeEndian.Uint32(data)))
+	}},
+	{"double", 8, false, func(data []byte) float64 {
+		return math.Float64frombits(binary.NativeEndian.Uint64(data))
+	}},
+}
+
+// Function findFitnessType returns the fitness type with the given name.
+func findFitnessType(name string) fitnessType {
+	for _, ft := range fitnessTypes {
+		if ft.name == name {
+			return ft
+		}
+	}
+	log.Fatalf("Fatal error: unknown fitness type %q.", name)
+	return fitnessType{}
+}
+
+// Function buildOptions returns the OpenCL compiler options defining the
+// fitness type and the tolerance of fitness comparisons. Integer fitness
+// values are always compared exactly, and the alternate format of %#g keeps
+// the decimal point a float literal requires, e.g. 0.00000f for 0.
+func (ft fitnessType) buildOptions(tolerance float64) string {
+	options := []string{"-D FITNESS_TYPE=" + ft.name}
+
+	switch {
+	case ft.integer:
+		options = append(options, "-D FITNESS_TOLERANCE=0")
+	case ft.name == "float":
+		options = append(options, fmt.Sprintf("-D FITNESS_TOLERANCE=%#gf", tolerance))
+	default:
+		options = append(options, fmt.Sprintf("-D FITNESS_TOLERANCE=%g", tolerance))
+	}
+
+	if ft.name == "double" {
+		options = append(options, "-D FITNESS_DOUBLE")
+	}
+
+	return strings.Join(options, " ")
+}
+
+// Function decodeAll converts the raw fitness buffer read from the compute
+// device into host fitness values.
+func (ft fitnessType) decodeAll(data []byte, dest []float64) {
+	for i := range dest {
+		dest[i] = ft.decode(data[i*ft.size : (i+1)*ft.size])
+	}
+}
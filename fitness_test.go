@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func TestFitnessDecode(t *testing.T) {
+	data := make([]byte, 8)
+
+	binary.NativeEndian.PutUint32(data, 7)
+	if actual := findFitnessType("uint").decode(data); actual != 7 {
+		t.Errorf("uint decode = %v, expected 7.", actual)
+	}
+
+	binary.NativeEndian.PutUint32(data, uint32(0xfffffffd))
+	if actual := findFitnessType("int").decode(data); actual != -3 {
+		t.Errorf("int decode = %v, expected -3.", actual)
+	}
+
+	binary.NativeEndian.PutUint32(data, math.Float32bits(-1.5))
+	if actual := findFitnessType("float").decode(data); actual != -1.5 {
+		t.Errorf("float decode = %v, expected -1.5.", actual)
+	}
+
+	binary.NativeEndian.PutUint64(data, math.Float64bits(0.1))
+	if actual := findFitnessType("double").decode(data); actual != 0.1 {
+		t.Errorf("double decode = %v, expected 0.1.", actual)
+	}
+}
+
+var buildOptionsTests = []struct {
+	name      string
+	tolerance float64
+	expected  string
+}{
+	{"uint", 1e-6, "-D FITNESS_TYPE=uint -D FITNESS_TOLERANCE=0"},
+	{"float", 1e-6, "-D FITNESS_TYPE=float -D FITNESS_TOLERANCE=1.00000e-06f"},
+	{"float", 0, "-D FITNESS_TYPE=float -D FITNESS_TOLERANCE=0.00000f"},
+	{"float", 1, "-D FITNESS_TYPE=float -D FITNESS_TOLERANCE=1.00000f"},
+	{"double", 1e-6, "-D FITNESS_TYPE=double -D FITNESS_TOLERANCE=1e-06 -D FITNESS_DOUBLE"},
+	{"double", 0, "-D FITNESS_TYPE=double -D FITNESS_TOLERANCE=0 -D FITNESS_DOUBLE"},
+}
+
+func TestFitnessBuildOptions(t *testing.T) {
+	for _, test := range buildOptionsTests {
+		if actual := findFitnessType(test.name).buildOptions(test.tolerance); actual != test.expected {
+			t.Errorf("buildOptions(%s, %v) = %q, expected %q.", test.name, test.tolerance, actual, test.expected)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package ga
+
+import (
+	"fmt"
+	"github.com/Morenim/gom-opencl/bitset"
+	"math/rand"
+)
+
+// Categorical is the genotype of discrete variables over the values 0 to
+// Alphabet-1. Every variable is packed into a field of Width() consecutive
+// bits of the bit-string of a solution, so populations, mixing and the
+// device encoding work on bits as before. A binary genotype has an
+// alphabet of two and a width of one.
+type Categorical struct {
+	Alphabet int
+}
+
+// Width returns the number of bits per variable.
+func (c Categorical) Width() int {
+	return bitset.FieldWidth(c.Alphabet)
+}
+
+// Variables returns the number of variables packed into a bit-string of
+// the given length.
+func (c Categorical) Variables(length int) int {
+	return length / c.Width()
+}
+
+// Value returns the value of variable i.
+func (c Categorical) Value(bits bitset.BitSet, i int) int {
+	return bitset.Field(bits, i, c.Width())
+}
+
+// SetValue sets variable i to value.
+func (c Categorical) SetValue(bits bitset.BitSet, i, value int) {
+	bitset.SetField(bits, i, c.Width(), value)
+}
+
+// Check returns an error if a field of the bit-string holds a value outside
+// the alphabet, which fits whenever the alphabet is not a power of two.
+func (c Categorical) Check(bits bitset.BitSet) error {
+	for i := 0; i < c.Variables(bits.Len()); i++ {
+		if value := c.Value(bits, i); value >= c.Alphabet {
+			format := "ga: variable %d has value %d outside the alphabet of %d"
+			return fmt.Errorf(format, i, value, c.Alphabet)
+		}
+	}
+	return nil
+}
+
+// Expand returns the sorted bit indices of every subset of variables,
+// which must be sorted as well.
+func (c Categorical) Expand(subsets [][]int) [][]int {
+	width := c.Width()
+	if width == 1 {
+		return subsets
+	}
+
+	expanded := make([][]int, len(subsets))
+	for s, subset := range subsets {
+		expanded[s] = make([]int, 0, len(subset)*width)
+		for _, i := range subset {
+			for j := 0; j < width; j++ {
+				expanded[s] = append(expanded[s], i*width+j)
+			}
+		}
+	}
+	return expanded
+}
+
+// Initialize draws the value of every variable uniformly from the
+// alphabet.
+func (c Categorical) Initialize(pop *Population, r *rand.Rand) error {
+	n := c.Variables(pop.Length())
+	parallel(pop, r, func(i int, r *rand.Rand) {
+		for j := 0; j < n; j++ {
+			c.SetValue(pop.Solutions[i].Bits, j, r.Intn(c.Alphabet))
+		}
+	})
+	return nil
+}
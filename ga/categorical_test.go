@@ -0,0 +1,63 @@
+package ga
+
+import (
+	"github.com/Morenim/gom-opencl/bitset"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestCategoricalInitialize(t *testing.T) {
+	c := Categorical{Alphabet: 5}
+	pop, _ := InitPopulation(200, 10*c.Width(), c, rand.New(rand.NewSource(1)))
+
+	counts := make([]int, 8)
+	for _, solution := range pop.Solutions {
+		for i := 0; i < c.Variables(pop.Length()); i++ {
+			counts[c.Value(solution.Bits, i)]++
+		}
+	}
+
+	for value, count := range counts {
+		if (value < c.Alphabet) != (count > 0) {
+			t.Errorf("Categorical alphabet %d drew value %d %d times.", c.Alphabet, value, count)
+		}
+	}
+}
+
+var expandTests = []struct {
+	alphabet int
+	subsets  [][]int
+	expected [][]int
+}{
+	{2, [][]int{{0}, {1, 3}}, [][]int{{0}, {1, 3}}},
+	{3, [][]int{{0}, {1, 3}}, [][]int{{0, 1}, {2, 3, 6, 7}}},
+	{5, [][]int{{1}, {0, 2}}, [][]int{{4, 5, 6, 7}, {0, 1, 2, 3, 8, 9, 10, 11}}},
+}
+
+func TestCategoricalExpand(t *testing.T) {
+	for _, test := range expandTests {
+		c := Categorical{Alphabet: test.alphabet}
+		if actual := c.Expand(test.subsets); !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("Categorical{%d}.Expand(%v) = %v, expected %v.", test.alphabet, test.subsets, actual, test.expected)
+		}
+	}
+}
+
+func TestCategoricalCheck(t *testing.T) {
+	c := Categorical{Alphabet: 3}
+
+	for _, test := range []struct {
+		bits  string
+		valid bool
+	}{
+		{"00011010", true},
+		{"00110010", false},
+		{"11000000", false},
+	} {
+		bits, _ := bitset.FromString(test.bits)
+		if err := c.Check(bits); (err == nil) != test.valid {
+			t.Errorf("Check(%s) = %v, expected valid %v.", test.bits, err, test.valid)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package ga
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/Morenim/gom-opencl/bitset"
+	"github.com/Morenim/gom-opencl/problem"
+	"io"
+	"math/rand"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Initializer sets the bits of every solution in a population of the
+// desired size and length. All randomness is drawn from r.
+type Initializer interface {
+	Initialize(pop *Population, r *rand.Rand) error
+}
+
+// Solutions are initialized in parallel in chunks of this size, each with
+// its own random source, so the result does not depend on the number of
+// goroutines.
+const chunkSize = 256
+
+// Function parallel calls f for every solution in the population, passing
+// the random source of the solution's chunk. Chunks are processed in
+// parallel when the population holds more than one chunk.
+func parallel(pop *Population, r *rand.Rand, f func(i int, r *rand.Rand)) {
+	numChunks := (pop.Size() + chunkSize - 1) / chunkSize
+	seeds := make([]int64, numChunks)
+	for i := range seeds {
+		seeds[i] = r.Int63()
+	}
+
+	chunk := func(c int) {
+		cr := rand.New(rand.NewSource(seeds[c]))
+		for i := c * chunkSize; i < (c+1)*chunkSize && i < pop.Size(); i++ {
+			f(i, cr)
+		}
+	}
+
+	if numChunks == 1 {
+		chunk(0)
+		return
+	}
+
+	var wg sync.WaitGroup
+	chunks := make(chan int)
+	for w := 0; w < runtime.GOMAXPROCS(0); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range chunks {
+				chunk(c)
+			}
+		}()
+	}
+	for c := 0; c < numChunks; c++ {
+		chunks <- c
+	}
+	close(chunks)
+	wg.Wait()
+}
+
+// Uniform draws every bit uniformly at random.
+type Uniform struct{}
+
+func (Uniform) Initialize(pop *Population, r *rand.Rand) error {
+	n := bitset.UInt32Len(pop.Length())
+	parallel(pop, r, func(i int, r *rand.Rand) {
+		words := make([]uint32, n)
+		for j := range words {
+			words[j] = r.Uint32()
+		}
+		pop.Solutions[i].Bits.LoadUInt32s(words)
+	})
+	return nil
+}
+
+// Balanced sets exactly half of the alleles at every locus to one, at
+// random positions, similar to Latin hypercube sampling. For odd population
+// sizes the remaining solution is drawn uniformly.
+type Balanced struct{}
+
+func (Balanced) Initialize(pop *Population, r *rand.Rand) error {
+	for _, solution := range pop.Solutions {
+		solution.Bits.ClearRange(0, pop.Length())
+	}
+	for j := 0; j < pop.Length(); j++ {
+		ones := pop.Size() / 2
+		if pop.Size()%2 == 1 {
+			ones += r.Intn(2)
+		}
+		for _, i := range r.Perm(pop.Size())[:ones] {
+			pop.Solutions[i].Bits.Set(j)
+		}
+	}
+	return nil
+}
+
+// Seeded copies known solutions into the first solutions of the population
+// and initializes the remainder with Rest. Seeds of a categorical Genotype
+// must hold values within its alphabet, and the zero value is binary.
+type Seeded struct {
+	Solutions []bitset.BitSet
+	Rest      Initializer
+	Genotype  Categorical
+}
+
+func (s Seeded) Initialize(pop *Population, r *rand.Rand) error {
+	if len(s.Solutions) > pop.Size() {
+		format := "ga: %d seed solutions exceed the population size %d"
+		return fmt.Errorf(format, len(s.Solutions), pop.Size())
+	}
+
+	if err := s.Rest.Initialize(pop, r); err != nil {
+		return err
+	}
+
+	for i, bits := range s.Solutions {
+		if bits.Len() != pop.Length() {
+			format := "ga: seed solution %d has length %d, expected %d"
+			return fmt.Errorf(format, i, bits.Len(), pop.Length())
+		}
+		if s.Genotype.Alphabet > 2 {
+			if err := s.Genotype.Check(bits); err != nil {
+				return fmt.Errorf("%v in seed solution %d", err, i)
+			}
+		}
+		pop.Solutions[i].Bits = bits.Clone()
+	}
+	return nil
+}
+
+// ReadSolutions reads one solution per line in the notation of
+// bitset.FromString. Empty lines and lines starting with # are skipped.
+func ReadSolutions(reader io.Reader) ([]bitset.BitSet, error) {
+	var solutions []bitset.BitSet
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(nil, 1<<24)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		bits, err := bitset.FromString(text)
+		if err != nil {
+			return nil, fmt.Errorf("ga: line %d: %v", line, err)
+		}
+		solutions = append(solutions, bits)
+	}
+
+	return solutions, scanner.Err()
+}
+
+// HillClimbed initializes the population with Base and improves every
+// solution with a first-improvement bit-flip local search, visiting the
+// bits in random order until no single flip improves the fitness. The
+// fitness of every solution is set as a side effect.
+type HillClimbed struct {
+	Problem problem.Problem
+	Base    Initializer
+}
+
+func (h HillClimbed) Initialize(pop *Population, r *rand.Rand) error {
+	if err := h.Base.Initialize(pop, r); err != nil {
+		return err
+	}
+
+	parallel(pop, r, func(i int, r *rand.Rand) {
+		solution := &pop.Solutions[i]
+		solution.Fitness, _ = h.Problem.Evaluate(solution.Bits)
+
+		for improved := true; improved; {
+			improved = false
+			for _, j := range r.Perm(pop.Length()) {
+				solution.Bits.Flip(j)
+				if fitness, _ := h.Problem.Evaluate(solution.Bits); fitness > solution.Fitness {
+					solution.Fitness = fitness
+					improved = true
+				} else {
+					solution.Bits.Flip(j)
+				}
+			}
+		}
+	})
+	return nil
+}
@@ -0,0 +1,84 @@
+package ga
+
+import (
+	"fmt"
+	"github.com/Morenim/gom-opencl/problem"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestInitDeterministic(t *testing.T) {
+	// Large enough to be initialized in parallel.
+	a := NewPopulation(3*chunkSize+7, 100, rand.New(rand.NewSource(5)))
+	b := NewPopulation(3*chunkSize+7, 100, rand.New(rand.NewSource(5)))
+
+	if fmt.Sprint(a) != fmt.Sprint(b) {
+		t.Errorf("NewPopulation with equal seeds returned different populations.")
+	}
+}
+
+func TestBalanced(t *testing.T) {
+	for _, size := range []int{10, 11} {
+		pop, _ := InitPopulation(size, 40, Balanced{}, rand.New(rand.NewSource(1)))
+		for i, c := range pop.AlleleCounts() {
+			if c != size/2 && c != (size+1)/2 {
+				t.Errorf("Balanced locus %d has %d ones in %d solutions.", i, c, size)
+			}
+		}
+	}
+}
+
+func TestSeeded(t *testing.T) {
+	file := "# known solutions\n1111000011110000\n\n0000111100001111\n"
+	solutions, err := ReadSolutions(strings.NewReader(file))
+	if err != nil || len(solutions) != 2 {
+		t.Fatalf("ReadSolutions() = %v, %v, expected 2 solutions.", solutions, err)
+	}
+
+	init := Seeded{Solutions: solutions, Rest: Uniform{}}
+	pop, err := InitPopulation(8, 16, init, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("InitPopulation() returned error %q.", err)
+	}
+	for i, bits := range solutions {
+		if !pop.Solutions[i].Bits.Equal(bits) {
+			t.Errorf("Solution %d = %v, expected seed %v.", i, pop.Solutions[i].Bits, bits)
+		}
+	}
+
+	if _, err := InitPopulation(8, 12, init, rand.New(rand.NewSource(1))); err == nil {
+		t.Errorf("InitPopulation() accepted seeds of the wrong length.")
+	}
+	if _, err := InitPopulation(1, 16, init, rand.New(rand.NewSource(1))); err == nil {
+		t.Errorf("InitPopulation() accepted more seeds than solutions.")
+	}
+
+	// The fields of an alphabet of 3 also fit the value 3.
+	init.Genotype = Categorical{Alphabet: 3}
+	init.Rest = init.Genotype
+	if _, err := InitPopulation(8, 16, init, rand.New(rand.NewSource(1))); err == nil {
+		t.Errorf("InitPopulation() accepted seeds outside the alphabet.")
+	}
+}
+
+func TestHillClimbed(t *testing.T) {
+	trap := problem.DeceptiveTrap(4)
+	init := HillClimbed{Problem: trap, Base: Uniform{}}
+	pop, _ := InitPopulation(32, 24, init, rand.New(rand.NewSource(1)))
+
+	// Every solution must be a local optimum with a correct fitness.
+	for i, solution := range pop.Solutions {
+		fitness, _ := trap.Evaluate(solution.Bits)
+		if fitness != solution.Fitness {
+			t.Errorf("Solution %d has fitness %v, expected %v.", i, solution.Fitness, fitness)
+		}
+		for j := 0; j < pop.Length(); j++ {
+			bits := solution.Bits.Clone()
+			bits.Flip(j)
+			if f, _ := trap.Evaluate(bits); f > fitness {
+				t.Errorf("Solution %d improves by flipping bit %d.", i, j)
+			}
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package ga
+
+import (
+	"fmt"
+	"github.com/Morenim/gom-opencl/problem"
+	"math/rand"
+	"sort"
+)
+
+// Split divides the population into n islands of equal size. The islands
+// are views of consecutive solutions, sharing the solutions of the
+// population until their solution slices are replaced.
+func (pop *Population) Split(n int) ([]*Population, error) {
+	if n < 1 || pop.Size()%n != 0 {
+		return nil, fmt.Errorf("ga: cannot split %d solutions into %d equal islands", pop.Size(), n)
+	}
+
+	size := pop.Size() / n
+	islands := make([]*Population, n)
+	for i := range islands {
+		islands[i] = &Population{Solutions: pop.Solutions[i*size : (i+1)*size : (i+1)*size]}
+	}
+	return islands, nil
+}
+
+// Join stores the solutions of the islands consecutively in a new
+// solution slice of pop, in the layout of Split. The bit-strings are
+// shared, not copied.
+func Join(islands []*Population, pop *Population) {
+	size := 0
+	for _, island := range islands {
+		size += island.Size()
+	}
+
+	pop.Solutions = make([]Solution, 0, size)
+	for _, island := range islands {
+		pop.Solutions = append(pop.Solutions, island.Solutions...)
+	}
+}
+
+// A Topology determines to which islands every island sends migrants.
+type Topology interface {
+	Neighbours(island, islands int, r *rand.Rand) []int
+}
+
+// Ring sends migrants to the next island, and the last island to the first.
+type Ring struct{}
+
+func (Ring) Neighbours(island, islands int, r *rand.Rand) []int {
+	if islands < 2 {
+		return nil
+	}
+	return []int{(island + 1) % islands}
+}
+
+// FullyConnected sends migrants to every other island.
+type FullyConnected struct{}
+
+func (FullyConnected) Neighbours(island, islands int, r *rand.Rand) []int {
+	var neighbours []int
+	for i := 0; i < islands; i++ {
+		if i != island {
+			neighbours = append(neighbours, i)
+		}
+	}
+	return neighbours
+}
+
+// RandomTopology sends migrants to Degree distinct other islands drawn anew
+// at every migration.
+type RandomTopology struct {
+	Degree int
+}
+
+func (t RandomTopology) Neighbours(island, islands int, r *rand.Rand) []int {
+	var neighbours []int
+	for _, i := range r.Perm(islands) {
+		if len(neighbours) == t.Degree {
+			break
+		}
+		if i != island {
+			neighbours = append(neighbours, i)
+		}
+	}
+	return neighbours
+}
+
+// Selection methods choosing the emigrants of an island.
+const (
+	SelectBest = iota
+	SelectRandom
+)
+
+// Replacement methods choosing the solutions replaced by immigrants.
+const (
+	ReplaceWorst = iota
+	ReplaceRandom
+)
+
+// A Migration describes how islands exchange solutions. Every island sends
+// copies of Count emigrants to each of its neighbours in the topology.
+// Immigrants only replace worse solutions. Solutions are ranked by
+// constraint domination if Problem is a problem.ConstrainedProblem, and by
+// fitness otherwise.
+type Migration struct {
+	Topology    Topology
+	Count       int
+	Selection   int
+	Replacement int
+	Problem     problem.Problem
+}
+
+// A migrant is a solution together with its constraint violation.
+type migrant struct {
+	Solution
+	violation float64
+}
+
+// Function better tests whether migrant a is better than migrant b under
+// constraint domination.
+func better(a, b migrant) bool {
+	return problem.ConstraintGt(a.Fitness, a.violation, b.Fitness, b.violation, 0)
+}
+
+// Function migrants pairs the solutions of the island with their
+// constraint violations.
+func (m Migration) migrants(island *Population) []migrant {
+	migrants := make([]migrant, island.Size())
+	for i, solution := range island.Solutions {
+		migrants[i] = migrant{solution, problem.Violation(m.Problem, solution.Bits)}
+	}
+	return migrants
+}
+
+// Migrate exchanges solutions between the islands. Emigrants are chosen
+// from the islands before any immigrant arrives. Migrate returns for every
+// island the indices of the solutions that were replaced.
+func Migrate(islands []*Population, m Migration, r *rand.Rand) [][]int {
+	residents := make([][]migrant, len(islands))
+	for i, island := range islands {
+		residents[i] = m.migrants(island)
+	}
+
+	emigrants := make([][]migrant, len(islands))
+	for i := range islands {
+		for _, index := range m.choose(residents[i], r) {
+			emigrant := residents[i][index]
+			emigrant.Bits = emigrant.Bits.Clone()
+			emigrants[i] = append(emigrants[i], emigrant)
+		}
+	}
+
+	immigrants := make([][]migrant, len(islands))
+	for i := range islands {
+		for _, neighbour := range m.Topology.Neighbours(i, len(islands), r) {
+			immigrants[neighbour] = append(immigrants[neighbour], emigrants[i]...)
+		}
+	}
+
+	replaced := make([][]int, len(islands))
+	for i, island := range islands {
+		// The best immigrants arrive first, so they are kept if the island
+		// is too small for all of them.
+		arrivals := immigrants[i]
+		sort.SliceStable(arrivals, func(a, b int) bool {
+			return better(arrivals[a], arrivals[b])
+		})
+
+		seen := make(map[int]bool)
+		for _, immigrant := range arrivals {
+			index := m.target(residents[i], seen, r)
+			if index < 0 || !better(immigrant, residents[i][index]) {
+				continue
+			}
+			seen[index] = true
+			island.Solutions[index] = immigrant.Solution
+			residents[i][index] = immigrant
+			replaced[i] = append(replaced[i], index)
+		}
+	}
+
+	return replaced
+}
+
+// Function choose returns the indices of Count solutions of the island,
+// either the best ones or uniformly random ones.
+func (m Migration) choose(island []migrant, r *rand.Rand) []int {
+	order := r.Perm(len(island))
+	if m.Selection == SelectBest {
+		sort.SliceStable(order, func(a, b int) bool {
+			return better(island[order[a]], island[order[b]])
+		})
+	}
+	if m.Count < len(order) {
+		order = order[:m.Count]
+	}
+	return order
+}
+
+// Function target returns the index of the solution an immigrant replaces,
+// skipping solutions replaced before, or -1 if none is left.
+func (m Migration) target(island []migrant, seen map[int]bool, r *rand.Rand) int {
+	target := -1
+	candidates := 0
+	for i, solution := range island {
+		if seen[i] {
+			continue
+		}
+		candidates++
+		switch m.Replacement {
+		case ReplaceWorst:
+			if target < 0 || better(island[target], solution) {
+				target = i
+			}
+		case ReplaceRandom:
+			// Reservoir sampling over the remaining solutions.
+			if r.Intn(candidates) == 0 {
+				target = i
+			}
+		}
+	}
+	return target
+}
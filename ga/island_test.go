@@ -0,0 +1,136 @@
+package ga
+
+import (
+	"fmt"
+	"github.com/Morenim/gom-opencl/bitset"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// Function islandsWithFitness returns islands of the given size whose
+// solutions have fitness values 0, 1, 2, ... in order.
+func islandsWithFitness(t *testing.T, n, size int) (*Population, []*Population) {
+	pop := NewPopulation(n*size, 16, rand.New(rand.NewSource(1)))
+	for i := range pop.Solutions {
+		pop.Solutions[i].Fitness = float64(i)
+	}
+	islands, err := pop.Split(n)
+	if err != nil {
+		t.Fatalf("Split(%d) returned error %q.", n, err)
+	}
+	return pop, islands
+}
+
+func TestSplitJoin(t *testing.T) {
+	pop, islands := islandsWithFitness(t, 4, 5)
+
+	for i, island := range islands {
+		if island.Size() != 5 || island.Solutions[0].Fitness != float64(5*i) {
+			t.Errorf("Island %d = %v, expected solutions %d to %d.", i, island, 5*i, 5*i+4)
+		}
+	}
+
+	joined := new(Population)
+	Join(islands, joined)
+	if fmt.Sprint(joined) != fmt.Sprint(pop) {
+		t.Errorf("Join(Split(%v)) = %v.", pop, joined)
+	}
+
+	if _, err := pop.Split(3); err == nil {
+		t.Errorf("Split(3) of %d solutions did not return an error.", pop.Size())
+	}
+}
+
+var topologyTests = []struct {
+	topology Topology
+	island   int
+	expected []int
+}{
+	{Ring{}, 0, []int{1}},
+	{Ring{}, 3, []int{0}},
+	{FullyConnected{}, 2, []int{0, 1, 3}},
+}
+
+func TestTopologies(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for _, test := range topologyTests {
+		actual := test.topology.Neighbours(test.island, 4, r)
+		if fmt.Sprint(actual) != fmt.Sprint(test.expected) {
+			t.Errorf("%T.Neighbours(%d, 4) = %v, expected %v.", test.topology, test.island, actual, test.expected)
+		}
+	}
+
+	for island := 0; island < 4; island++ {
+		neighbours := RandomTopology{Degree: 2}.Neighbours(island, 4, r)
+		sort.Ints(neighbours)
+		if len(neighbours) != 2 || neighbours[0] == neighbours[1] || neighbours[0] == island || neighbours[1] == island {
+			t.Errorf("RandomTopology{2}.Neighbours(%d, 4) = %v.", island, neighbours)
+		}
+	}
+}
+
+func TestMigrateBestToWorst(t *testing.T) {
+	pop, islands := islandsWithFitness(t, 3, 4)
+	m := Migration{Topology: Ring{}, Count: 2, Selection: SelectBest, Replacement: ReplaceWorst}
+
+	replaced := Migrate(islands, m, rand.New(rand.NewSource(1)))
+
+	// Island 0 receives the best solutions of island 2, but islands 1 and
+	// 2 only receive solutions worse than their own.
+	expected := [][]int{{0, 1}, nil, nil}
+	if fmt.Sprint(replaced) != fmt.Sprint(expected) {
+		t.Fatalf("Migrate() replaced %v, expected %v.", replaced, expected)
+	}
+
+	fitness := []float64{11, 10, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}
+	for i, solution := range pop.Solutions {
+		if solution.Fitness != fitness[i] {
+			t.Errorf("Solution %d has fitness %v after migration, expected %v.", i, solution.Fitness, fitness[i])
+		}
+	}
+
+	// Migrants are copies of their originals.
+	pop.Solutions[0].Bits.Flip(0)
+	if pop.Solutions[0].Bits.Equal(pop.Solutions[11].Bits) {
+		t.Errorf("Migrant shares its bit-string with the emigrant.")
+	}
+}
+
+// A lowBitConstraint is violated by solutions whose lowest bit is set.
+type lowBitConstraint struct{}
+
+func (lowBitConstraint) Evaluate(bits bitset.BitSet) (float64, bool) {
+	return 0, false
+}
+
+func (lowBitConstraint) Violation(bits bitset.BitSet) float64 {
+	if bits.Has(0) {
+		return 1
+	}
+	return 0
+}
+
+func TestMigrateConstrained(t *testing.T) {
+	pop := newTestPopulation("0001", "0000", "0000", "0010")
+	for i, fitness := range []float64{10, 0, 5, 1} {
+		pop.Solutions[i].Fitness = fitness
+	}
+	islands, _ := pop.Split(2)
+
+	m := Migration{Topology: Ring{}, Count: 1, Selection: SelectBest, Replacement: ReplaceWorst, Problem: lowBitConstraint{}}
+	replaced := Migrate(islands, m, rand.New(rand.NewSource(1)))
+
+	// The infeasible solution of fitness 10 is the worst of island 0 and is
+	// replaced by the best of island 1, whereas the best of island 0 is
+	// the feasible solution of fitness 0, which is worse than both
+	// solutions of island 1.
+	expected := [][]int{{0}, nil}
+	if fmt.Sprint(replaced) != fmt.Sprint(expected) {
+		t.Fatalf("Migrate() replaced %v, expected %v.", replaced, expected)
+	}
+	if fitness := islands[0].Solutions[0].Fitness; fitness != 5 {
+		t.Errorf("Solution 0 has fitness %v after migration, expected 5.", fitness)
+	}
+}
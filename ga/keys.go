@@ -0,0 +1,118 @@
+package ga
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// KeySolution is a permutation encoded by random keys, a vector of reals
+// in [0, 1] decoded by sorting: element i of the permutation is the index
+// of the i-th smallest key.
+type KeySolution struct {
+	Fitness float64
+	Keys    []float64
+}
+
+func (s KeySolution) String() string {
+	return fmt.Sprintf("%v %v", s.Permutation(), s.Fitness)
+}
+
+// Permutation returns the indices of the keys in ascending order of their
+// keys. Equal keys keep their index order.
+func (s KeySolution) Permutation() []int {
+	perm := make([]int, len(s.Keys))
+	for i := range perm {
+		perm[i] = i
+	}
+	sort.SliceStable(perm, func(a, b int) bool { return s.Keys[perm[a]] < s.Keys[perm[b]] })
+	return perm
+}
+
+// Reencode replaces the keys by sorted uniformly random keys that decode
+// to the same permutation, so keys stay spread over [0, 1] and copied keys
+// can still be inserted between them.
+func (s KeySolution) Reencode(r *rand.Rand) {
+	keys := make([]float64, len(s.Keys))
+	for i := range keys {
+		keys[i] = r.Float64()
+	}
+	sort.Float64s(keys)
+
+	for i, index := range s.Permutation() {
+		s.Keys[index] = keys[i]
+	}
+}
+
+// KeyPopulation is a collection of random-key solutions.
+type KeyPopulation struct {
+	Solutions []KeySolution
+}
+
+// NewKeyPopulation returns an unevaluated population of uniformly random
+// keys, i.e. uniformly random permutations, drawn from r.
+func NewKeyPopulation(size, length int, r *rand.Rand) *KeyPopulation {
+	pop := &KeyPopulation{Solutions: make([]KeySolution, size)}
+	for i := range pop.Solutions {
+		pop.Solutions[i].Keys = make([]float64, length)
+		for j := range pop.Solutions[i].Keys {
+			pop.Solutions[i].Keys[j] = r.Float64()
+		}
+	}
+	return pop
+}
+
+func (pop *KeyPopulation) Size() int {
+	return len(pop.Solutions)
+}
+
+func (pop *KeyPopulation) Length() int {
+	return len(pop.Solutions[0].Keys)
+}
+
+// Best returns the index of the solution with the highest fitness, or -1
+// if the population is empty. Ties are resolved by the lowest index.
+func (pop *KeyPopulation) Best() int {
+	best := -1
+	for i, solution := range pop.Solutions {
+		if best < 0 || solution.Fitness > pop.Solutions[best].Fitness {
+			best = i
+		}
+	}
+	return best
+}
+
+// MeanFitness returns the average fitness of the population.
+func (pop *KeyPopulation) MeanFitness() float64 {
+	sum := 0.0
+	for _, solution := range pop.Solutions {
+		sum += solution.Fitness
+	}
+	return sum / float64(pop.Size())
+}
+
+// Converged tests whether all solutions decode to the same permutation.
+func (pop *KeyPopulation) Converged() bool {
+	first := fmt.Sprint(pop.Solutions[0].Permutation())
+	for _, solution := range pop.Solutions[1:] {
+		if fmt.Sprint(solution.Permutation()) != first {
+			return false
+		}
+	}
+	return true
+}
+
+// Rescale maps the keys at indices linearly into a uniformly random
+// subinterval of [0, 1] of their current width, keeping their order.
+func Rescale(keys []float64, indices []int, r *rand.Rand) {
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, i := range indices {
+		low, high = math.Min(low, keys[i]), math.Max(high, keys[i])
+	}
+
+	offset := r.Float64() * (1 - (high - low))
+	for _, i := range indices {
+		keys[i] = offset + keys[i] - low
+	}
+}
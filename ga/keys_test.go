@@ -0,0 +1,42 @@
+package ga
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestKeyPermutation(t *testing.T) {
+	s := KeySolution{Keys: []float64{0.7, 0.1, 0.5, 0.1}}
+	if perm := s.Permutation(); !reflect.DeepEqual(perm, []int{1, 3, 2, 0}) {
+		t.Errorf("Permutation(%v) = %v, expected [1 3 2 0].", s.Keys, perm)
+	}
+}
+
+func TestReencodeKeepsPermutation(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	pop := NewKeyPopulation(20, 15, r)
+
+	for _, s := range pop.Solutions {
+		expected := s.Permutation()
+		s.Reencode(r)
+		if perm := s.Permutation(); !reflect.DeepEqual(perm, expected) {
+			t.Errorf("Reencode changed permutation %v into %v.", expected, perm)
+		}
+	}
+}
+
+func TestRescaleKeepsOrder(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		keys := []float64{0.9, 0.2, 0.4, 0.3}
+		Rescale(keys, []int{1, 2, 3}, r)
+
+		if !(keys[1] < keys[3] && keys[3] < keys[2]) {
+			t.Fatalf("Rescale changed the order of the keys: %v.", keys)
+		}
+		if keys[1] < 0 || keys[2] > 1 || keys[2]-keys[1] < 0.2-1e-12 || keys[2]-keys[1] > 0.2+1e-12 {
+			t.Fatalf("Rescale mapped [0.2, 0.4] to [%v, %v].", keys[1], keys[2])
+		}
+	}
+}
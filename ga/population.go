@@ -2,6 +2,8 @@ package ga
 
 import (
 	"fmt"
+	"github.com/Morenim/gom-opencl/bitset"
+	"math/rand"
 )
 
 // Population is a collection of solutions.
@@ -17,17 +19,48 @@ func (pop *Population) Length() int {
 	return pop.Solutions[0].Bits.Len()
 }
 
-// NewPopulation returns an unevaluated population.
-func NewPopulation(size, length int) *Population {
+// NewPopulation returns an unevaluated population with uniformly random
+// solutions drawn from r.
+func NewPopulation(size, length int, r *rand.Rand) *Population {
+	pop, _ := InitPopulation(size, length, Uniform{}, r)
+	return pop
+}
+
+// InitPopulation returns a population initialized by init. Solutions are
+// unevaluated unless the initializer evaluates them.
+func InitPopulation(size, length int, init Initializer, r *rand.Rand) (*Population, error) {
 	pop := new(Population)
 	pop.Solutions = make([]Solution, size)
 	for i := 0; i < size; i++ {
-		pop.Solutions[i] = randomSolution(length)
-		pop.Solutions[i].Fitness = 0.0
+		pop.Solutions[i].Bits = bitset.New(length)
 	}
-	return pop
+	if size == 0 {
+		return pop, nil
+	}
+	if err := init.Initialize(pop, r); err != nil {
+		return nil, err
+	}
+	return pop, nil
 }
 
 func (pop *Population) String() string {
 	return fmt.Sprintf("%v", pop.Solutions)
 }
+
+// PutUInt32s flattens the population into dest, storing every solution as
+// bitset.UInt32Len(Length()) consecutive 32-bit words.
+func (pop *Population) PutUInt32s(dest []uint32) {
+	n := bitset.UInt32Len(pop.Length())
+	for i, solution := range pop.Solutions {
+		solution.Bits.PutUInt32s(dest[i*n : (i+1)*n])
+	}
+}
+
+// LoadUInt32s overwrites the bits of every solution in place with the words
+// in src, which uses the layout of PutUInt32s. Fitness values are unchanged.
+func (pop *Population) LoadUInt32s(src []uint32) {
+	n := bitset.UInt32Len(pop.Length())
+	for i, solution := range pop.Solutions {
+		solution.Bits.LoadUInt32s(src[i*n : (i+1)*n])
+	}
+}
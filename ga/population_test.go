@@ -0,0 +1,131 @@
+package ga
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Morenim/gom-opencl/bitset"
+	"math/rand"
+	"testing"
+)
+
+// Function populationToSlicePerBit is the reference flattening of a
+// population, testing every bit individually.
+func populationToSlicePerBit(pop *Population, dest []uint32) {
+	n := bitset.UInt32Len(pop.Length())
+	for i := range dest[:pop.Size()*n] {
+		dest[i] = 0
+	}
+	for i, solution := range pop.Solutions {
+		for j := 0; j < pop.Length(); j++ {
+			if solution.Bits.Has(j) {
+				dest[i*n+j>>5] |= 1 << (uint(j) & 31)
+			}
+		}
+	}
+}
+
+// Function sliceToPopulationAlloc is the reference unflattening of a
+// population, allocating a new bit-set for every solution.
+func sliceToPopulationAlloc(src []uint32, pop *Population) {
+	n := bitset.UInt32Len(pop.Length())
+	for i := range pop.Solutions {
+		pop.Solutions[i].Bits, _ = bitset.FromUInt32s(src[i*n:(i+1)*n], pop.Length())
+	}
+}
+
+func TestPopulationUInt32s(t *testing.T) {
+	for _, length := range []int{1, 31, 32, 33, 64, 100} {
+		pop := NewPopulation(16, length, rand.New(rand.NewSource(1)))
+		size := pop.Size() * bitset.UInt32Len(length)
+
+		expected := make([]uint32, size)
+		populationToSlicePerBit(pop, expected)
+
+		actual := make([]uint32, size)
+		pop.PutUInt32s(actual)
+
+		for i := range expected {
+			if actual[i] != expected[i] {
+				t.Fatalf("PutUInt32s()[%d] = %x, expected %x.", i, actual[i], expected[i])
+			}
+		}
+
+		loaded := NewPopulation(16, length, rand.New(rand.NewSource(2)))
+		loaded.LoadUInt32s(actual)
+
+		if fmt.Sprint(loaded) != fmt.Sprint(pop) {
+			t.Errorf("LoadUInt32s() = %v, expected %v.", loaded, pop)
+		}
+	}
+}
+
+func TestPopulationJSON(t *testing.T) {
+	pop := NewPopulation(8, 70, rand.New(rand.NewSource(1)))
+	pop.Solutions[3].Fitness = 12.5
+
+	data, err := json.Marshal(pop)
+	if err != nil {
+		t.Fatalf("json.Marshal() returned error %q.", err)
+	}
+
+	decoded := new(Population)
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Fatalf("json.Unmarshal(%s) returned error %q.", data, err)
+	}
+
+	if fmt.Sprint(decoded) != fmt.Sprint(pop) {
+		t.Errorf("json.Unmarshal() = %v, expected %v.", decoded, pop)
+	}
+}
+
+func TestPopulationUInt32sAllocations(t *testing.T) {
+	pop := NewPopulation(64, 256, rand.New(rand.NewSource(1)))
+	data := make([]uint32, pop.Size()*bitset.UInt32Len(pop.Length()))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		pop.PutUInt32s(data)
+		pop.LoadUInt32s(data)
+	})
+	if allocs != 0 {
+		t.Errorf("Population marshalling allocated %v times, expected 0.", allocs)
+	}
+}
+
+func benchmarkPopulation() (*Population, []uint32) {
+	pop := NewPopulation(1024, 1000, rand.New(rand.NewSource(1)))
+	data := make([]uint32, pop.Size()*bitset.UInt32Len(pop.Length()))
+	pop.PutUInt32s(data)
+	return pop, data
+}
+
+func BenchmarkPopulationToSlicePerBit(b *testing.B) {
+	pop, data := benchmarkPopulation()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		populationToSlicePerBit(pop, data)
+	}
+}
+
+func BenchmarkPopulationPutUInt32s(b *testing.B) {
+	pop, data := benchmarkPopulation()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pop.PutUInt32s(data)
+	}
+}
+
+func BenchmarkSliceToPopulationAlloc(b *testing.B) {
+	pop, data := benchmarkPopulation()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sliceToPopulationAlloc(data, pop)
+	}
+}
+
+func BenchmarkPopulationLoadUInt32s(b *testing.B) {
+	pop, data := benchmarkPopulation()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pop.LoadUInt32s(data)
+	}
+}
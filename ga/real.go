@@ -0,0 +1,75 @@
+package ga
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// RealSolution is a vector of reals solving a maximization problem.
+type RealSolution struct {
+	Fitness float64
+	Values  []float64
+}
+
+func (s RealSolution) String() string {
+	return fmt.Sprintf("%v %v", s.Values, s.Fitness)
+}
+
+// RealPopulation is a collection of real-valued solutions.
+type RealPopulation struct {
+	Solutions []RealSolution
+}
+
+// NewRealPopulation returns an unevaluated population of vectors drawn
+// uniformly from [low, high] in every variable.
+func NewRealPopulation(size, length int, low, high float64, r *rand.Rand) *RealPopulation {
+	pop := &RealPopulation{Solutions: make([]RealSolution, size)}
+	for i := range pop.Solutions {
+		pop.Solutions[i].Values = make([]float64, length)
+		for j := range pop.Solutions[i].Values {
+			pop.Solutions[i].Values[j] = low + (high-low)*r.Float64()
+		}
+	}
+	return pop
+}
+
+func (pop *RealPopulation) Size() int {
+	return len(pop.Solutions)
+}
+
+func (pop *RealPopulation) Length() int {
+	return len(pop.Solutions[0].Values)
+}
+
+// Best returns the index of the solution with the highest fitness, or -1
+// if the population is empty. Ties are resolved by the lowest index.
+func (pop *RealPopulation) Best() int {
+	best := -1
+	for i, solution := range pop.Solutions {
+		if best < 0 || solution.Fitness > pop.Solutions[best].Fitness {
+			best = i
+		}
+	}
+	return best
+}
+
+// MeanFitness returns the average fitness of the population.
+func (pop *RealPopulation) MeanFitness() float64 {
+	sum := 0.0
+	for _, solution := range pop.Solutions {
+		sum += solution.Fitness
+	}
+	return sum / float64(pop.Size())
+}
+
+// StdFitness returns the standard deviation of the fitness values in the
+// population.
+func (pop *RealPopulation) StdFitness() float64 {
+	mean := pop.MeanFitness()
+	sum := 0.0
+	for _, solution := range pop.Solutions {
+		sum += (solution.Fitness - mean) * (solution.Fitness - mean)
+	}
+	return math.Sqrt(sum / float64(pop.Size()))
+}
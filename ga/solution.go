@@ -1,9 +1,9 @@
 package ga
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/Morenim/gom-opencl/bitset"
-	"math/rand"
 )
 
 // Solution is a bitstring solution to a maximization optimization problem.
@@ -16,14 +16,20 @@ func (s Solution) String() string {
 	return fmt.Sprintf("%v %v", s.Bits, s.Fitness)
 }
 
-func randomSolution(length int) Solution {
-	var s Solution
-	s.Bits = bitset.New(length)
-	for i := 0; i < length; i++ {
-		if rand.Float32() > 0.5 {
-			s.Bits.Set(i)
-		}
+// UnmarshalJSON decodes a solution encoded by json.Marshal. The bits are
+// decoded into a new dense bit-set, because Bits is an interface.
+func (s *Solution) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Fitness float64
+		Bits    string
 	}
-	//s.Objective, s.Constraint = evaluate(s.Bits)
-	return s
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	bits, err := bitset.FromString(raw.Bits)
+	if err != nil {
+		return err
+	}
+	s.Fitness, s.Bits = raw.Fitness, bits
+	return nil
 }
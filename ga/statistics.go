@@ -0,0 +1,143 @@
+package ga
+
+import (
+	"github.com/Morenim/gom-opencl/problem"
+	"math"
+)
+
+// Best returns the index of the solution with the highest fitness, or -1
+// if the population is empty. Ties are resolved by the lowest index.
+func (pop *Population) Best() int {
+	best := -1
+	for i, solution := range pop.Solutions {
+		if best < 0 || solution.Fitness > pop.Solutions[best].Fitness {
+			best = i
+		}
+	}
+	return best
+}
+
+// BestFeasible returns the index of the best solution under constraint
+// domination for the problem, which is Best unless the problem is a
+// problem.ConstrainedProblem, or -1 if the population is empty.
+func (pop *Population) BestFeasible(p problem.Problem) int {
+	best := -1
+	bestViolation := 0.0
+	for i, solution := range pop.Solutions {
+		violation := problem.Violation(p, solution.Bits)
+		if best < 0 || problem.ConstraintGt(solution.Fitness, violation, pop.Solutions[best].Fitness, bestViolation, 0) {
+			best, bestViolation = i, violation
+		}
+	}
+	return best
+}
+
+// BestFitness returns the highest fitness in the population.
+func (pop *Population) BestFitness() float64 {
+	best := math.Inf(-1)
+	for _, solution := range pop.Solutions {
+		best = math.Max(best, solution.Fitness)
+	}
+	return best
+}
+
+// WorstFitness returns the lowest fitness in the population.
+func (pop *Population) WorstFitness() float64 {
+	worst := math.Inf(1)
+	for _, solution := range pop.Solutions {
+		worst = math.Min(worst, solution.Fitness)
+	}
+	return worst
+}
+
+// MeanFitness returns the average fitness of the population, or 0 if the
+// population is empty.
+func (pop *Population) MeanFitness() float64 {
+	if pop.Size() == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, solution := range pop.Solutions {
+		sum += solution.Fitness
+	}
+	return sum / float64(pop.Size())
+}
+
+// StdFitness returns the standard deviation of the fitness values in the
+// population, or 0 if the population is empty.
+func (pop *Population) StdFitness() float64 {
+	if pop.Size() == 0 {
+		return 0
+	}
+	mean := pop.MeanFitness()
+	sum := 0.0
+	for _, solution := range pop.Solutions {
+		sum += (solution.Fitness - mean) * (solution.Fitness - mean)
+	}
+	return math.Sqrt(sum / float64(pop.Size()))
+}
+
+// AlleleCounts returns the number of solutions with a one at every locus,
+// or nil if the population is empty.
+func (pop *Population) AlleleCounts() []int {
+	if pop.Size() == 0 {
+		return nil
+	}
+	counts := make([]int, pop.Length())
+	for _, solution := range pop.Solutions {
+		for i := solution.Bits.NextSet(0); i >= 0; i = solution.Bits.NextSet(i + 1) {
+			counts[i]++
+		}
+	}
+	return counts
+}
+
+// AlleleFrequencies returns the fraction of solutions with a one at every
+// locus, or an empty slice if the population is empty.
+func (pop *Population) AlleleFrequencies() []float64 {
+	counts := pop.AlleleCounts()
+	freqs := make([]float64, len(counts))
+	for i, c := range counts {
+		freqs[i] = float64(c) / float64(pop.Size())
+	}
+	return freqs
+}
+
+// MeanDistance returns the mean Hamming distance over all pairs of
+// solutions. It is computed from the allele counts, since every locus with
+// c ones contributes c * (n - c) differing pairs.
+func (pop *Population) MeanDistance() float64 {
+	n := pop.Size()
+	if n < 2 {
+		return 0.0
+	}
+	sum := 0
+	for _, c := range pop.AlleleCounts() {
+		sum += c * (n - c)
+	}
+	return float64(sum) / float64(n*(n-1)/2)
+}
+
+// Unique returns the number of distinct genotypes in the population.
+func (pop *Population) Unique() int {
+	seen := make(map[string]bool, pop.Size())
+	for _, solution := range pop.Solutions {
+		data, _ := solution.Bits.MarshalBinary()
+		seen[string(data)] = true
+	}
+	return len(seen)
+}
+
+// Converged tests whether all solutions in the population are identical,
+// which holds for an empty population.
+func (pop *Population) Converged() bool {
+	if pop.Size() == 0 {
+		return true
+	}
+	for _, solution := range pop.Solutions[1:] {
+		if !solution.Bits.Equal(pop.Solutions[0].Bits) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,93 @@
+package ga
+
+import (
+	"github.com/Morenim/gom-opencl/bitset"
+	"math"
+	"testing"
+)
+
+func newTestPopulation(bits ...string) *Population {
+	pop := new(Population)
+	for i, s := range bits {
+		b, _ := bitset.FromString(s)
+		pop.Solutions = append(pop.Solutions, Solution{Fitness: float64(i), Bits: b})
+	}
+	return pop
+}
+
+func TestStatistics(t *testing.T) {
+	pop := newTestPopulation("0011", "0101", "0011", "1111")
+	pop.Solutions[1].Fitness = 5
+
+	if actual := pop.Best(); actual != 1 {
+		t.Errorf("Best() = %d, expected 1.", actual)
+	}
+	if actual := pop.BestFitness(); actual != 5 {
+		t.Errorf("BestFitness() = %v, expected 5.", actual)
+	}
+	if actual := pop.WorstFitness(); actual != 0 {
+		t.Errorf("WorstFitness() = %v, expected 0.", actual)
+	}
+	if actual := pop.MeanFitness(); actual != 2.5 {
+		t.Errorf("MeanFitness() = %v, expected 2.5.", actual)
+	}
+	if actual, expected := pop.StdFitness(), math.Sqrt(3.25); math.Abs(actual-expected) > 1e-12 {
+		t.Errorf("StdFitness() = %v, expected %v.", actual, expected)
+	}
+
+	expectedFreqs := []float64{1.0, 0.75, 0.5, 0.25}
+	for i, f := range pop.AlleleFrequencies() {
+		if f != expectedFreqs[i] {
+			t.Errorf("AlleleFrequencies()[%d] = %v, expected %v.", i, f, expectedFreqs[i])
+		}
+	}
+
+	// Pairwise distances: 2, 0, 2, 2, 2, 2.
+	if actual := pop.MeanDistance(); actual != 10.0/6.0 {
+		t.Errorf("MeanDistance() = %v, expected %v.", actual, 10.0/6.0)
+	}
+	if actual := pop.Unique(); actual != 3 {
+		t.Errorf("Unique() = %d, expected 3.", actual)
+	}
+	if pop.Converged() {
+		t.Errorf("Converged() = true, expected false.")
+	}
+}
+
+func TestConverged(t *testing.T) {
+	pop := newTestPopulation("1010", "1010", "1010")
+	if !pop.Converged() {
+		t.Errorf("Converged() = false, expected true.")
+	}
+	if actual := pop.Unique(); actual != 1 {
+		t.Errorf("Unique() = %d, expected 1.", actual)
+	}
+	if actual := pop.MeanDistance(); actual != 0 {
+		t.Errorf("MeanDistance() = %v, expected 0.", actual)
+	}
+}
+
+func TestEmptyStatistics(t *testing.T) {
+	pop := new(Population)
+	if !pop.Converged() {
+		t.Errorf("Converged() of an empty population = false, expected true.")
+	}
+	if mean, std := pop.MeanFitness(), pop.StdFitness(); mean != 0 || std != 0 {
+		t.Errorf("MeanFitness(), StdFitness() of an empty population = %v, %v, expected 0, 0.", mean, std)
+	}
+	if counts, freqs := pop.AlleleCounts(), pop.AlleleFrequencies(); len(counts) != 0 || len(freqs) != 0 {
+		t.Errorf("AlleleCounts(), AlleleFrequencies() of an empty population = %v, %v, expected empty slices.", counts, freqs)
+	}
+}
+
+func TestBestFeasible(t *testing.T) {
+	pop := newTestPopulation("0001", "0000", "0010", "0011")
+	pop.Solutions[0].Fitness = 10
+
+	if best := pop.BestFeasible(lowBitConstraint{}); best != 2 {
+		t.Errorf("BestFeasible() = %d, expected the feasible solution 2.", best)
+	}
+	if best := pop.BestFeasible(nil); best != 0 {
+		t.Errorf("BestFeasible(nil) = %d, expected %d.", best, 0)
+	}
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"github.com/Morenim/gom-opencl/bitset"
+	"github.com/Morenim/gom-opencl/ga"
+	"github.com/Morenim/gom-opencl/problem"
+	"math/rand"
+	randv2 "math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Optimizer performs GOMEA on the host, mixing with the same gene-pool
+// optimal mixing as the gom kernel. All randomness is drawn from Rand, so
+// several optimizers can run concurrently and reproducibly.
+type Optimizer struct {
+	Problem    problem.Problem
+	Population *ga.Population
+	Rand       *rand.Rand
+
+	// Changes are accepted if they lower the fitness by at most Tolerance
+	// below that of the parent, or of the last improvement on it, and count
+	// as improvements if they raise it by more than Tolerance.
+	// For a ConstrainedProblem changes are compared by constraint
+	// domination, so fitness only decides between feasible solutions.
+	Tolerance float64
+
+	// Workers is the number of goroutines mixing slices of the population,
+	// sized by their measured throughput like devices of the OpenCL
+	// backend. Every solution draws its donors from its own random source,
+	// so the result does not depend on the number of workers.
+	Workers    int
+	throughput []float64
+
+	// Genotype packs categorical variables into the bit-strings. Linkage
+	// is learned over its variables, and the zero value is binary.
+	Genotype ga.Categorical
+
+	// The linkage model learned in the last generation, over the problem
+	// variables, and the bit indices of every FOS subset.
+	Frequencies [][][]int
+	FOS         [][]int
+	Merges      []Merge
+	masks       [][]int
+
+	offspring *ga.Population
+
+	// Whether the solutions of the population and offspring are optimal,
+	// and their constraint violations.
+	optimal, offspringOptimal     []bool
+	violation, offspringViolation []float64
+}
+
+// NewOptimizer returns an optimizer for the population, evaluating every
+// solution in the population, at once if the problem is Batched.
+func NewOptimizer(p problem.Problem, pop *ga.Population, r *rand.Rand) *Optimizer {
+	o := &Optimizer{Problem: p, Population: pop, Rand: r}
+
+	o.offspring = &ga.Population{Solutions: make([]ga.Solution, pop.Size())}
+	o.optimal = make([]bool, pop.Size())
+	o.offspringOptimal = make([]bool, pop.Size())
+	o.violation = make([]float64, pop.Size())
+	o.offspringViolation = make([]float64, pop.Size())
+
+	for i, solution := range pop.Solutions {
+		o.violation[i] = problem.Violation(p, solution.Bits)
+	}
+
+	if batched, ok := p.(problem.Batched); ok {
+		solutions := make([]bitset.BitSet, pop.Size())
+		for i, solution := range pop.Solutions {
+			solutions[i] = solution.Bits
+		}
+
+		fitness, optimal := batched.EvaluateBatch(solutions)
+		for i := range pop.Solutions {
+			pop.Solutions[i].Fitness, o.optimal[i] = fitness[i], optimal[i]
+		}
+		return o
+	}
+
+	for i, solution := range pop.Solutions {
+		pop.Solutions[i].Fitness, o.optimal[i] = p.Evaluate(solution.Bits)
+	}
+
+	return o
+}
+
+// Replaced updates the solutions of the population at the indices, which
+// were replaced from outside the optimizer by evaluated solutions, e.g. by
+// migration. Their fitness is kept and only their violations are computed.
+// They are optimal if the problem is Bounded and they are feasible and
+// reach its optimum; an optimal immigrant was otherwise already reported by
+// the optimizer it left.
+func (o *Optimizer) Replaced(indices []int) {
+	bounded, ok := o.Problem.(problem.Bounded)
+	for _, i := range indices {
+		solution := o.Population.Solutions[i]
+		o.violation[i] = problem.Violation(o.Problem, solution.Bits)
+		o.optimal[i] = ok && o.violation[i] == 0 &&
+			solution.Fitness+o.Tolerance >= bounded.Optimum(solution.Bits.Len())
+	}
+}
+
+// Optimal tests whether the population contains an optimal solution.
+func (o *Optimizer) Optimal() bool {
+	for _, optimal := range o.optimal {
+		if optimal {
+			return true
+		}
+	}
+	return false
+}
+
+// Step performs a single generation: it learns the linkage tree of the
+// population and replaces every solution by the result of mixing it with
+// random donors. Step reports whether any solution improved and whether an
+// optimal solution was found.
+func (o *Optimizer) Step() (improved, optimal bool) {
+	o.Learn()
+	return o.Mix()
+}
+
+// Learn learns the linkage tree of the population.
+func (o *Optimizer) Learn() {
+	if o.Genotype.Alphabet > 2 {
+		o.Frequencies = CategoricalFrequencies(o.Population, o.Genotype)
+	} else {
+		o.Frequencies = Frequencies(o.Population)
+	}
+	o.FOS, o.Merges = LinkageTreeMerges(o.Population, o.Frequencies, o.Rand)
+	o.masks = o.Genotype.Expand(o.FOS)
+}
+
+// Mix replaces every solution by the result of mixing it with random
+// donors, using the linkage tree of the last call to Learn.
+func (o *Optimizer) Mix() (improved, optimal bool) {
+	pop := o.Population
+
+	workers := o.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	if len(o.throughput) != workers {
+		o.throughput = make([]float64, workers)
+	}
+
+	seeds := make([]uint64, pop.Size())
+	for i := range seeds {
+		seeds[i] = o.Rand.Uint64()
+	}
+
+	var wg sync.WaitGroup
+	improvements := make([]bool, workers)
+	offset := 0
+
+	for w, count := range balance(pop.Size(), o.throughput) {
+		wg.Add(1)
+		go func(w, offset, count int) {
+			defer wg.Done()
+			start := time.Now()
+			for i := offset; i < offset+count; i++ {
+				r := randv2.New(randv2.NewPCG(seeds[i], uint64(i)))
+				if o.mix(i, r) {
+					improvements[w] = true
+				}
+			}
+			o.throughput[w] = float64(count) / time.Since(start).Seconds()
+		}(w, offset, count)
+		offset += count
+	}
+	wg.Wait()
+
+	for _, better := range improvements {
+		improved = improved || better
+	}
+
+	// The offspring become the parents of the next generation.
+	pop.Solutions, o.offspring.Solutions = o.offspring.Solutions, pop.Solutions
+	o.optimal, o.offspringOptimal = o.offspringOptimal, o.optimal
+	o.violation, o.offspringViolation = o.offspringViolation, o.violation
+
+	return improved, o.Optimal()
+}
+
+// Function mix applies GOM to solution i of the population, storing the
+// result in the offspring. For every FOS subset the bits of a donor drawn
+// from r are copied into the offspring, and the change is kept unless it
+// makes the offspring worse than the parent, or the last improvement on
+// it, under constraint domination. Mix reports whether the offspring
+// improved on its parent.
+func (o *Optimizer) mix(i int, r *randv2.Rand) (improved bool) {
+	parent := o.Population.Solutions[i]
+	offspring := &o.offspring.Solutions[i]
+
+	offspring.Bits, offspring.Fitness = parent.Bits.Clone(), parent.Fitness
+	o.offspringOptimal[i] = o.optimal[i]
+	o.offspringViolation[i] = o.violation[i]
+
+	// The clone holds the offspring bits before the current change.
+	clone := parent.Bits.Clone()
+
+	// Changes are compared with the reference, the parent until a change
+	// improves on it, so accepted losses within the tolerance do not add up.
+	reference, referenceViolation := parent.Fitness, o.violation[i]
+
+	for _, subset := range o.masks {
+		donor := o.Population.Solutions[r.IntN(o.Population.Size())]
+
+		// Copying bits equal to those of the offspring changes nothing, so
+		// the evaluation is skipped.
+		if sameBits(offspring.Bits, donor.Bits, subset) {
+			continue
+		}
+
+		offspring.Bits.CopyBits(donor.Bits, subset)
+
+		fitness, optimal := o.Problem.Evaluate(offspring.Bits)
+		violation := problem.Violation(o.Problem, offspring.Bits)
+
+		if problem.ConstraintGeq(fitness, violation, reference, referenceViolation, o.Tolerance) {
+			clone.CopyBits(offspring.Bits, subset)
+			if problem.ConstraintGt(fitness, violation, reference, referenceViolation, o.Tolerance) {
+				improved = true
+				reference, referenceViolation = fitness, violation
+			}
+			offspring.Fitness, o.offspringOptimal[i] = fitness, optimal
+			o.offspringViolation[i] = violation
+		} else {
+			offspring.Bits.CopyBits(clone, subset)
+		}
+	}
+
+	return improved
+}
+
+// Function sameBits tests whether the bit-strings agree on the indices.
+func sameBits(a, b bitset.BitSet, indices []int) bool {
+	for _, i := range indices {
+		if a.Has(i) != b.Has(i) {
+			return false
+		}
+	}
+	return true
+}
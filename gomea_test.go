@@ -0,0 +1,246 @@
+package main
+
+import (
+	"fmt"
+	"github.com/Morenim/gom-opencl/bitset"
+	"github.com/Morenim/gom-opencl/ga"
+	"github.com/Morenim/gom-opencl/problem"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+// Function trajectory runs the Go backend for a number of generations and
+// records the population after every generation.
+func trajectory(seed int64, generations, workers int) []string {
+	r := rand.New(rand.NewSource(seed))
+	pop := ga.NewPopulation(64, 40, r)
+	optimizer := NewOptimizer(problem.DeceptiveTrap(4), pop, r)
+	optimizer.Workers = workers
+
+	states := []string{fmt.Sprint(pop)}
+	for i := 0; i < generations; i++ {
+		optimizer.Step()
+		states = append(states, fmt.Sprint(pop))
+	}
+	return states
+}
+
+func TestConcurrentRunsDeterministic(t *testing.T) {
+	const runs = 4
+
+	var wg sync.WaitGroup
+	trajectories := make([][]string, runs)
+
+	for i := 0; i < runs; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			trajectories[i] = trajectory(42, 10, 1)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 1; i < runs; i++ {
+		for g := range trajectories[0] {
+			if trajectories[i][g] != trajectories[0][g] {
+				t.Fatalf("Run %d diverged from run 0 in generation %d.", i, g)
+			}
+		}
+	}
+
+	if other := trajectory(43, 10, 1); fmt.Sprint(other) == fmt.Sprint(trajectories[0]) {
+		t.Errorf("Runs with different seeds produced identical trajectories.")
+	}
+}
+
+func TestInterleavedRunsIsolated(t *testing.T) {
+	seeds := []int64{42, 43}
+
+	// Every run steps in turn on the same goroutine, so any state shared
+	// between the runs changes their trajectories.
+	optimizers := make([]*Optimizer, len(seeds))
+	trajectories := make([][]string, len(seeds))
+	for i, seed := range seeds {
+		r := rand.New(rand.NewSource(seed))
+		pop := ga.NewPopulation(64, 40, r)
+		optimizers[i] = NewOptimizer(problem.DeceptiveTrap(4), pop, r)
+		optimizers[i].Workers = 1
+		trajectories[i] = []string{fmt.Sprint(pop)}
+	}
+	for g := 0; g < 10; g++ {
+		for i, optimizer := range optimizers {
+			optimizer.Step()
+			trajectories[i] = append(trajectories[i], fmt.Sprint(optimizer.Population))
+		}
+	}
+
+	for i, seed := range seeds {
+		expected := trajectory(seed, 10, 1)
+		for g := range expected {
+			if trajectories[i][g] != expected[g] {
+				t.Fatalf("Interleaved run %d diverged from a separate run in generation %d.", i, g)
+			}
+		}
+	}
+}
+
+func TestWorkersDeterministic(t *testing.T) {
+	expected := trajectory(42, 10, 1)
+
+	for _, workers := range []int{2, 3, 7} {
+		actual := trajectory(42, 10, workers)
+		for g := range expected {
+			if actual[g] != expected[g] {
+				t.Fatalf("%d workers diverged from 1 worker in generation %d.", workers, g)
+			}
+		}
+	}
+}
+
+func TestOptimizerSolvesTrap(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	pop := ga.NewPopulation(200, 20, r)
+	optimizer := NewOptimizer(problem.DeceptiveTrap(4), pop, r)
+
+	for generation := 0; generation < 50 && !optimizer.Optimal(); generation++ {
+		optimizer.Step()
+	}
+
+	if !optimizer.Optimal() {
+		t.Errorf("Optimizer did not solve the deceptive trap, best fitness %v.", pop.BestFitness())
+	}
+}
+
+// A countingTrap counts the evaluations of the deceptive trap.
+type countingTrap struct {
+	evaluations int
+}
+
+func (p *countingTrap) Evaluate(bits bitset.BitSet) (float64, bool) {
+	p.evaluations++
+	return problem.DeceptiveTrap(4).Evaluate(bits)
+}
+
+func TestMixSkipsUnchangedOffspring(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	pop := ga.NewPopulation(16, 20, r)
+	for i := range pop.Solutions {
+		pop.Solutions[i].Bits = pop.Solutions[0].Bits.Clone()
+	}
+
+	p := new(countingTrap)
+	optimizer := NewOptimizer(p, pop, r)
+	p.evaluations = 0
+
+	// All donors equal the parents, so no offspring changes.
+	optimizer.Step()
+	if p.evaluations != 0 {
+		t.Errorf("Mix() evaluated %d unchanged offspring.", p.evaluations)
+	}
+}
+
+func TestReplacedKeepsFitness(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	pop := ga.NewPopulation(16, 20, r)
+
+	p := new(countingTrap)
+	optimizer := NewOptimizer(p, pop, r)
+	p.evaluations = 0
+
+	pop.Solutions[3].Fitness = 42
+	optimizer.Replaced([]int{3})
+
+	if p.evaluations != 0 {
+		t.Errorf("Replaced() evaluated %d immigrants.", p.evaluations)
+	}
+	if pop.Solutions[3].Fitness != 42 {
+		t.Errorf("Replaced() changed the fitness to %v, expected 42.", pop.Solutions[3].Fitness)
+	}
+}
+
+func TestReplacedDetectsOptimum(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	pop := ga.NewPopulation(16, 20, r)
+	trap := problem.DeceptiveTrap(4)
+	optimizer := NewOptimizer(trap, pop, r)
+
+	bits := bitset.New(20)
+	bits.SetRange(0, bits.Len())
+	fitness, _ := trap.Evaluate(bits)
+	pop.Solutions[5] = ga.Solution{Bits: bits, Fitness: fitness}
+	optimizer.Replaced([]int{5})
+
+	if !optimizer.Optimal() {
+		t.Errorf("Replaced() missed the optimal immigrant with fitness %v.", fitness)
+	}
+}
+
+func TestOptimizerFindsFeasibleKnapsack(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	k := problem.RandomKnapsack(40, r)
+
+	// Random solutions fill about half the knapsack, so about half of them
+	// are infeasible initially.
+	pop := ga.NewPopulation(100, 40, r)
+	optimizer := NewOptimizer(k, pop, r)
+
+	for generation := 0; generation < 30; generation++ {
+		if improved, _ := optimizer.Step(); !improved {
+			break
+		}
+	}
+
+	for _, solution := range pop.Solutions {
+		if violation := k.Violation(solution.Bits); violation > 0 {
+			t.Fatalf("Solution %v violates the capacity by %v.", solution.Bits, violation)
+		}
+	}
+}
+
+func TestOptimizerSolvesKaryTrap(t *testing.T) {
+	genotype := ga.Categorical{Alphabet: 3}
+
+	r := rand.New(rand.NewSource(1))
+	pop, _ := ga.InitPopulation(300, 12*genotype.Width(), genotype, r)
+	optimizer := NewOptimizer(problem.KaryTrap{Alphabet: 3, K: 4}, pop, r)
+	optimizer.Genotype = genotype
+
+	for generation := 0; generation < 50 && !optimizer.Optimal(); generation++ {
+		optimizer.Step()
+	}
+
+	if !optimizer.Optimal() {
+		t.Errorf("Optimizer did not solve the k-ary trap, best fitness %v.", pop.BestFitness())
+	}
+	if len(optimizer.FOS) != 2*12-1 {
+		t.Errorf("Optimizer learned %d subsets over 12 variables, expected %d.", len(optimizer.FOS), 2*12-1)
+	}
+}
+
+func TestMixToleranceDoesNotAccumulate(t *testing.T) {
+	const tolerance = 1.5
+
+	r := rand.New(rand.NewSource(1))
+	pop := ga.NewPopulation(64, 40, r)
+
+	// A trap of size 1 is OneMax, so every subset of a few bits changes the
+	// fitness by less than the tolerance.
+	optimizer := NewOptimizer(problem.DeceptiveTrap(1), pop, r)
+	optimizer.Tolerance = tolerance
+
+	for generation := 0; generation < 5; generation++ {
+		parents := make([]float64, pop.Size())
+		for i, solution := range pop.Solutions {
+			parents[i] = solution.Fitness
+		}
+
+		optimizer.Step()
+
+		for i, solution := range pop.Solutions {
+			if solution.Fitness < parents[i]-tolerance {
+				t.Fatalf("Offspring %d has fitness %v, more than %v below its parent %v.", i, solution.Fitness, tolerance, parents[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"github.com/Morenim/gom-opencl/ga"
+	"github.com/Morenim/gom-opencl/problem"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+)
+
+// The kernel sources are embedded, so the executable runs from any
+// directory.
+//
+//go:embed kernels/*.cl
+var embeddedKernels embed.FS
+
+// The OpenCL program consists of the fitness definitions, the problem
+// kernel defining evaluate(), the random number generator and GOM.
+const (
+	fitnessKernel = "fitness.cl"
+	rngKernel     = "rng.cl"
+	gomKernel     = "gom.cl"
+	realKernel    = "real.cl"
+)
+
+var (
+	kernelComments = regexp.MustCompile(`(?s)//[^\n]*|/\*.*?\*/`)
+
+	// The signature of evaluate() required by the gom kernel, allowing
+	// address space and access qualifiers on the parameters.
+	evaluateSignature = regexp.MustCompile(`\bfitness_t\s+evaluate\s*\(` +
+		`\s*(?:(?:__)?(?:read_only|const)\s+)*(?:__)?global\s+(?:(?:__)?(?:read_only|const)\s+)*uint\s*\*\s*\w+\s*,` +
+		`\s*(?:(?:__)?(?:read_only|const)\s+)*uint\s+\w+\s*\)\s*\{`)
+)
+
+// Function readKernel returns the source of a kernel file, read from the
+// -kernel-dir directory if it contains the file and from the embedded
+// kernels otherwise. It also reports whether the file was user-supplied.
+func readKernel(name string) (source []byte, user bool, err error) {
+	if kernelDir != "" {
+		source, err = os.ReadFile(path.Join(kernelDir, name))
+		if err == nil {
+			return source, true, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, true, err
+		}
+	}
+
+	source, err = embeddedKernels.ReadFile(path.Join("kernels", name))
+	return source, false, err
+}
+
+// Function readProblemKernel returns the source of the problem kernel,
+// which is the -problem-kernel file if given. It also reports whether the
+// kernel was user-supplied.
+func readProblemKernel(name string) (source []byte, user bool, err error) {
+	if problemKernel != "" {
+		source, err = os.ReadFile(problemKernel)
+		return source, true, err
+	}
+	return readKernel(name)
+}
+
+// Function validateEvaluate tests whether a problem kernel defines
+// evaluate() with the signature called by the gom kernel.
+func validateEvaluate(source []byte) error {
+	if !evaluateSignature.Match(kernelComments.ReplaceAll(source, nil)) {
+		return errors.New("the kernel does not define " +
+			"fitness_t evaluate(global uint *solution, uint solution_length)")
+	}
+	return nil
+}
+
+// Function kernelSources returns the sources of the OpenCL program for the
+// problem kernel, in build order. User-supplied problem kernels are
+// validated before they are built, and reported as such.
+func kernelSources(problemName string) (sources [][]byte, user bool, err error) {
+	names := []string{fitnessKernel, problemName, rngKernel, gomKernel}
+	sources = make([][]byte, len(names))
+
+	for i, name := range names {
+		var source []byte
+		var userKernel bool
+
+		if i == 1 {
+			source, userKernel, err = readProblemKernel(name)
+		} else {
+			source, _, err = readKernel(name)
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("could not read the kernel source file %s: %v", name, err)
+		}
+
+		if userKernel {
+			if err := validateEvaluate(source); err != nil {
+				return nil, false, fmt.Errorf("%s: %v", name, err)
+			}
+			user = true
+		}
+
+		sources[i] = source
+	}
+
+	return sources, user, nil
+}
+
+// Function realKernelSources returns the sources of the program evaluating
+// a real-valued problem: the fitness definitions, the problem kernel
+// defining objective() and the evaluate_real kernel.
+func realKernelSources(problemName string) ([][]byte, error) {
+	names := []string{fitnessKernel, problemName, realKernel}
+	sources := make([][]byte, len(names))
+
+	for i, name := range names {
+		source, _, err := readKernel(name)
+		if err != nil {
+			return nil, fmt.Errorf("could not read the kernel source file %s: %v", name, err)
+		}
+		sources[i] = source
+	}
+
+	return sources, nil
+}
+
+// Function categoricalDefinitions returns the OpenCL definitions of the
+// genotype, which precede every problem kernel: the ALPHABET size and the
+// FIELD_WIDTH in bits of every variable.
+func categoricalDefinitions(c ga.Categorical) []byte {
+	return []byte(fmt.Sprintf("#define ALPHABET %d\n#define FIELD_WIDTH %d\n", c.Alphabet, c.Width()))
+}
+
+// Function knapsackDefinitions returns the OpenCL definitions of the
+// knapsack instance, which precede knapsack.cl in the program.
+func knapsackDefinitions(k *problem.Knapsack) []byte {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "#define KNAPSACK_ITEMS %d\n", len(k.Profits))
+	fmt.Fprintf(&b, "#define KNAPSACK_CAPACITY %s\n", strconv.FormatFloat(k.Capacity, 'f', -1, 64))
+
+	for _, array := range []struct {
+		name   string
+		values []float64
+	}{{"knapsack_profits", k.Profits}, {"knapsack_weights", k.Weights}} {
+		fmt.Fprintf(&b, "constant fitness_t %s[] = {", array.name)
+		for i, v := range array.values {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+		}
+		b.WriteString("};\n")
+	}
+
+	return b.Bytes()
+}
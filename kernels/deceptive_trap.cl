@@ -21,9 +21,9 @@ uint trap(read_only uint solution, int offset)
 
 // Implements the evaluation function for the
 // concatenated deceptive trap function.
-uint evaluate(read_only global uint *solution, read_only uint solution_length)
+fitness_t evaluate(read_only global uint *solution, read_only uint solution_length)
 {
-  uint fitness = 0;
+  fitness_t fitness = 0;
   uint k = 4;
 
   for (uint i = 0; i < solution_length; i += k)
@@ -0,0 +1,29 @@
+// Defines the type of fitness values, chosen by the host at build time
+// with -D FITNESS_TYPE=<uint|int|float|double>, and the tolerance used
+// when comparing them with -D FITNESS_TOLERANCE=<value>.
+
+#ifndef FITNESS_TYPE
+#define FITNESS_TYPE uint
+#endif
+
+#ifndef FITNESS_TOLERANCE
+#define FITNESS_TOLERANCE 0
+#endif
+
+#ifdef FITNESS_DOUBLE
+#pragma OPENCL EXTENSION cl_khr_fp64 : enable
+#endif
+
+typedef FITNESS_TYPE fitness_t;
+
+// Returns whether fitness a is at least fitness b, up to the tolerance.
+bool fitness_geq(fitness_t a, fitness_t b)
+{
+  return a + FITNESS_TOLERANCE >= b;
+}
+
+// Returns whether fitness a exceeds fitness b by more than the tolerance.
+bool fitness_gt(fitness_t a, fitness_t b)
+{
+  return a > b + FITNESS_TOLERANCE;
+}
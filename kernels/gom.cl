@@ -6,10 +6,45 @@ int ints_per_solution(int solution_length)
 constant uint bit_mod = sizeof(uint) * 8 - 1;
 constant uint bit_quot = 5;
 
-// Implements the core functionality of the GOMEA algorithm.
-kernel void gom(global uint *population, const uint population_size, const uint solution_length, global uint *clones, global uint *fos, global write_only char *improvs, global write_only uint *offspring)
+// Problems with constraints define CONSTRAINED and violation(), which
+// returns zero for feasible solutions. Other problems are never violated.
+#ifndef CONSTRAINED
+fitness_t violation(read_only global uint *solution, read_only uint solution_length)
+{
+  return 0;
+}
+#endif
+
+// Returns whether a solution with fitness a and violation va is at least
+// as good as one with fitness b and violation vb under constraint
+// domination: feasible solutions are compared by fitness, a feasible
+// solution beats an infeasible one, and a lower violation beats a higher.
+bool constrained_geq(fitness_t a, fitness_t va, fitness_t b, fitness_t vb)
+{
+  if (va == 0 && vb == 0)
+    return fitness_geq(a, b);
+  return va <= vb;
+}
+
+// Returns whether a solution with fitness a and violation va is better
+// than one with fitness b and violation vb under constraint domination.
+bool constrained_gt(fitness_t a, fitness_t va, fitness_t b, fitness_t vb)
+{
+  if (va == 0 && vb == 0)
+    return fitness_gt(a, b);
+  return va < vb;
+}
+
+// Implements the core functionality of the GOMEA algorithm. The population
+// consists of islands of island_size consecutive solutions, which each have
+// their own FOS: the forest starts with the offset of the FOS of every
+// island. Donors are drawn from the island of the solution.
+kernel void gom(global uint *population, const uint population_size, const uint solution_length, global uint *clones, global uint *forest, global write_only char *improvs, global write_only uint *offspring, global write_only fitness_t *fitnesses, const uint island_size)
 {
   int gid = get_global_id (0);
+  uint island = gid / island_size;
+  uint island_offset = island * island_size;
+  global uint *fos = forest + forest[island];
   uint4 rng_state = rng(gid);
   uint num_ints_solution = ints_per_solution(solution_length);
   uint intdex = (gid * num_ints_solution);
@@ -22,14 +57,20 @@ kernel void gom(global uint *population, const uint population_size, const uint
     offspring[i] = population[i];
   }
 
-  uint fitness = evaluate(&offspring[intdex], solution_length);
+  fitness_t fitness = evaluate(&offspring[intdex], solution_length);
+  fitness_t currentViolation = violation(&offspring[intdex], solution_length);
+
+  // Changes are compared with the reference, the parent until a change
+  // improves on it, so accepted losses within the tolerance do not add up.
+  fitness_t referenceFitness = fitness;
+  fitness_t referenceViolation = currentViolation;
 
   uint fos_size = fos[0];
   uint fos_ptr = 1;
 
   for (uint fos_index = 0; fos_index < fos_size; ++fos_index)
   {
-    uint rand = randrange(&rng_state, 0, population_size - 1);
+    uint rand = island_offset + randrange(&rng_state, 0, island_size - 1);
     uint num_masks = fos[fos_ptr];
 
     for (uint j = 0; j < num_masks; j++)
@@ -40,9 +81,10 @@ kernel void gom(global uint *population, const uint population_size, const uint
       clones[intdex + mask_index] = (offspring[intdex + mask_index] & ~mask) | changes;
     }
 
-    uint newFitness = evaluate(clones + intdex, solution_length);
+    fitness_t newFitness = evaluate(clones + intdex, solution_length);
+    fitness_t newViolation = violation(clones + intdex, solution_length);
 
-    if (newFitness >= fitness)
+    if (constrained_geq(newFitness, newViolation, referenceFitness, referenceViolation))
     {
       for (uint j = 0; j < num_masks; j++)
       {
@@ -50,11 +92,15 @@ kernel void gom(global uint *population, const uint population_size, const uint
         offspring[mask_index] = clones[mask_index];
       }
 
-      if (newFitness > fitness)
+      if (constrained_gt(newFitness, newViolation, referenceFitness, referenceViolation))
       {
-        fitness = newFitness;
         improvs[gid] = true;
+        referenceFitness = newFitness;
+        referenceViolation = newViolation;
       }
+
+      fitness = newFitness;
+      currentViolation = newViolation;
     }
     else
     {
@@ -67,4 +113,14 @@ kernel void gom(global uint *population, const uint population_size, const uint
 
     fos_ptr += 2 * num_masks + 1;
   }
+
+  fitnesses[gid] = fitness;
+}
+
+// Evaluates every solution of the population, so the host can verify that
+// its fitness function agrees with evaluate().
+kernel void evaluate_all(global uint *population, const uint solution_length, global write_only fitness_t *fitnesses)
+{
+  int gid = get_global_id (0);
+  fitnesses[gid] = evaluate(population + gid * ints_per_solution(solution_length), solution_length);
 }
@@ -1,8 +1,8 @@
 // Implements the evaluation function for the
 // Hierarchical If and only If function.
-uint evaluate(read_only global uint *solution, read_only uint solution_length)
+fitness_t evaluate(read_only global uint *solution, read_only uint solution_length)
 {
-  uint fitness = 0;
+  fitness_t fitness = 0;
   uint k = 4;
   uint block_size = 2;
 
@@ -0,0 +1,30 @@
+// Returns the value of variable i, packed into fields of FIELD_WIDTH bits.
+uint variable(read_only global uint *solution, uint i)
+{
+  uint bit = i * FIELD_WIDTH;
+  return (solution[bit >> 5] >> (bit & 31)) & ((1u << FIELD_WIDTH) - 1);
+}
+
+// Implements the evaluation function for the concatenated deceptive trap
+// function over categorical variables with values 0 to ALPHABET - 1. A
+// trap counts the variables set to the highest value.
+fitness_t evaluate(read_only global uint *solution, read_only uint solution_length)
+{
+  fitness_t fitness = 0;
+  uint k = 4;
+  uint variables = solution_length / FIELD_WIDTH;
+
+  for (uint i = 0; i + k <= variables; i += k)
+  {
+    uint t = 0;
+    for (uint j = i; j < i + k; j++)
+      t += (variable(solution, j) == ALPHABET - 1) ? 1 : 0;
+
+    if (t == k)
+      fitness += k;
+    else
+      fitness += k - t - 1;
+  }
+
+  return fitness;
+}
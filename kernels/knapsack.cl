@@ -0,0 +1,40 @@
+// Implements the 0/1 knapsack problem. The host prepends the instance:
+// KNAPSACK_ITEMS, KNAPSACK_CAPACITY and the constant arrays
+// knapsack_profits and knapsack_weights.
+#define CONSTRAINED
+
+// Returns the total profit of the selected items and stores their total
+// weight in weight.
+fitness_t knapsack_totals(read_only global uint *solution, read_only uint solution_length, fitness_t *weight)
+{
+  fitness_t profit = 0;
+  uint items = min(solution_length, (uint) KNAPSACK_ITEMS);
+  *weight = 0;
+
+  for (uint i = 0; i < items; i++)
+  {
+    if (solution[i >> 5] & (1u << (i & 31)))
+    {
+      profit += knapsack_profits[i];
+      *weight += knapsack_weights[i];
+    }
+  }
+
+  return profit;
+}
+
+// Implements the evaluation function, the total profit of the selected
+// items.
+fitness_t evaluate(read_only global uint *solution, read_only uint solution_length)
+{
+  fitness_t weight;
+  return knapsack_totals(solution, solution_length, &weight);
+}
+
+// Returns the weight of the selected items in excess of the capacity.
+fitness_t violation(read_only global uint *solution, read_only uint solution_length)
+{
+  fitness_t weight;
+  knapsack_totals(solution, solution_length, &weight);
+  return weight > KNAPSACK_CAPACITY ? weight - KNAPSACK_CAPACITY : 0;
+}
@@ -0,0 +1,9 @@
+// Evaluates the objective of every real-valued solution of the population,
+// stored as consecutive vectors of length values. The problem kernel
+// defines objective(), which may read problem data such as a rotation
+// matrix.
+kernel void evaluate_real(global const fitness_t *population, const uint length, global const fitness_t *data, global write_only fitness_t *objectives)
+{
+  int gid = get_global_id (0);
+  objectives[gid] = objective(population + gid * length, length, data);
+}
@@ -0,0 +1,14 @@
+// Implements the generalised Rosenbrock function.
+fitness_t objective(global const fitness_t *x, uint n, global const fitness_t *data)
+{
+  fitness_t sum = 0;
+
+  for (uint i = 0; i + 1 < n; i++)
+  {
+    fitness_t a = x[i + 1] - x[i] * x[i];
+    fitness_t b = 1 - x[i];
+    sum += 100 * a * a + b * b;
+  }
+
+  return sum;
+}
@@ -0,0 +1,18 @@
+// Implements the ellipsoid function with condition number 10^6 on the
+// variables rotated by the n x n matrix in data, stored by rows.
+fitness_t objective(global const fitness_t *x, uint n, global const fitness_t *data)
+{
+  fitness_t sum = 0;
+
+  for (uint i = 0; i < n; i++)
+  {
+    fitness_t y = 0;
+    for (uint j = 0; j < n; j++)
+      y += data[i * n + j] * x[j];
+
+    fitness_t weight = n > 1 ? pow((fitness_t) 10, 6 * (fitness_t) i / (n - 1)) : 1;
+    sum += weight * y * y;
+  }
+
+  return sum;
+}
@@ -0,0 +1,10 @@
+// Implements the sphere function, the sum of the squared variables.
+fitness_t objective(global const fitness_t *x, uint n, global const fitness_t *data)
+{
+  fitness_t sum = 0;
+
+  for (uint i = 0; i < n; i++)
+    sum += x[i] * x[i];
+
+  return sum;
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"github.com/Morenim/gom-opencl/ga"
+	"github.com/Morenim/gom-opencl/problem"
+	"strings"
+	"testing"
+)
+
+var evaluateTests = []struct {
+	source   string
+	expected bool
+}{
+	{"fitness_t evaluate(read_only global uint *solution, read_only uint solution_length)\n{", true},
+	{"fitness_t evaluate(global uint* x, uint n) {", true},
+	{"fitness_t evaluate(__global const uint *x, const uint n){", true},
+	{"// fitness_t evaluate(global uint *x, uint n) {\n", false},
+	{"/* fitness_t evaluate(global uint *x, uint n) { */", false},
+	{"fitness_t evaluate(global uint *x, uint n);", false},
+	{"fitness_t evaluate(uint *x, uint n) {", false},
+	{"uint evaluate(global uint *x, uint n) {", false},
+	{"fitness_t evaluate(global uint *x) {", false},
+}
+
+func TestValidateEvaluate(t *testing.T) {
+	for _, test := range evaluateTests {
+		err := validateEvaluate([]byte(test.source))
+		if (err == nil) != test.expected {
+			t.Errorf("validateEvaluate(%q) = %v, expected valid %t.", test.source, err, test.expected)
+		}
+	}
+}
+
+func TestEmbeddedKernels(t *testing.T) {
+	for _, p := range problems {
+		sources, _, err := kernelSources(p.clSource)
+		if err != nil {
+			t.Fatalf("kernelSources(%q) returned error %q.", p.clSource, err)
+		}
+
+		if err := validateEvaluate(sources[1]); err != nil {
+			t.Errorf("Embedded kernel %s: %v", p.clSource, err)
+		}
+	}
+}
+
+func TestKnapsackDefinitions(t *testing.T) {
+	k := &problem.Knapsack{Profits: []float64{10, 20}, Weights: []float64{5, 7.5}, Capacity: 6}
+
+	expected := "#define KNAPSACK_ITEMS 2\n" +
+		"#define KNAPSACK_CAPACITY 6\n" +
+		"constant fitness_t knapsack_profits[] = {10, 20};\n" +
+		"constant fitness_t knapsack_weights[] = {5, 7.5};\n"
+
+	if actual := string(knapsackDefinitions(k)); actual != expected {
+		t.Errorf("knapsackDefinitions(%v) = %q, expected %q.", k, actual, expected)
+	}
+}
+
+func TestCategoricalDefinitions(t *testing.T) {
+	expected := "#define ALPHABET 5\n#define FIELD_WIDTH 4\n"
+	if actual := string(categoricalDefinitions(ga.Categorical{Alphabet: 5})); actual != expected {
+		t.Errorf("categoricalDefinitions(5) = %q, expected %q.", actual, expected)
+	}
+}
+
+func TestRealKernels(t *testing.T) {
+	for _, p := range realProblems {
+		sources, err := realKernelSources(p.clSource)
+		if err != nil {
+			t.Fatalf("realKernelSources(%q) returned error %q.", p.clSource, err)
+		}
+
+		if !strings.Contains(string(sources[1]), "fitness_t objective(") {
+			t.Errorf("Embedded kernel %s does not define objective().", p.clSource)
+		}
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"sort"
 
+	"github.com/Morenim/gom-opencl/bitset"
 	"github.com/Morenim/gom-opencl/ga"
 )
 
@@ -71,6 +73,54 @@ func frequency(pop *ga.Population, indices []int) []int {
 	return perms
 }
 
+// Function categoricalFrequency counts the frequencies of the joint values
+// of the categorical variables indicated by indices, like frequency counts
+// those of bits. Joint values are indexed by the fields of the variables,
+// which also hold values outside the alphabet. Unless the population is at
+// least as large as the number of joint values, only the observed joint
+// values are counted, in increasing order, so large alphabets take no more
+// memory than the population. The entropy is the same either way.
+func categoricalFrequency(pop *ga.Population, c ga.Categorical, indices []int) []int {
+	base := 1 << uint(c.Width())
+	numPerms := 1
+	for range indices {
+		numPerms *= base
+	}
+
+	joint := func(bits bitset.BitSet) int {
+		index := 0
+		for j := len(indices) - 1; j >= 0; j-- {
+			index = index*base + c.Value(bits, indices[j])
+		}
+		return index
+	}
+
+	if numPerms <= pop.Size() {
+		perms := make([]int, numPerms)
+		for i := 0; i < pop.Size(); i++ {
+			perms[joint(pop.Solutions[i].Bits)]++
+		}
+		return perms
+	}
+
+	counts := make(map[int]int)
+	for i := 0; i < pop.Size(); i++ {
+		counts[joint(pop.Solutions[i].Bits)]++
+	}
+
+	observed := make([]int, 0, len(counts))
+	for index := range counts {
+		observed = append(observed, index)
+	}
+	sort.Ints(observed)
+
+	perms := make([]int, len(observed))
+	for i, index := range observed {
+		perms[i] = counts[index]
+	}
+	return perms
+}
+
 // Compute the Entropy information measure from an array of problem
 // variable frequencies.
 func entropy(freqs []int, size int) float64 {
@@ -102,13 +152,33 @@ func Frequencies(pop *ga.Population) [][][]int {
 	return freqs
 }
 
+// Function CategoricalFrequencies counts the frequencies of the values of
+// every categorical variable and every pair of variables, in the layout of
+// Frequencies, so the linkage tree is learned over variables instead of
+// bits.
+func CategoricalFrequencies(pop *ga.Population, c ga.Categorical) [][][]int {
+	n := c.Variables(pop.Length())
+	freqs := make([][][]int, n)
+
+	for i := 0; i < n; i++ {
+		freqs[i] = make([][]int, i+1)
+		for j := 0; j < i; j++ {
+			freqs[i][j] = categoricalFrequency(pop, c, []int{i, j})
+		}
+
+		freqs[i][i] = categoricalFrequency(pop, c, []int{i})
+	}
+
+	return freqs
+}
+
 // Function distanceMatrix computes the mutual information between every
 // pair of problem variables. The frequencies of bits in the population
 // are used for the probabilites.
 func distanceMatrix(pop *ga.Population, frequencies [][][]int) *matrix {
-	distances := newMatrix(pop.Length())
+	distances := newMatrix(len(frequencies))
 
-	for i := 0; i < pop.Length(); i++ {
+	for i := 0; i < len(frequencies); i++ {
 		for j := 0; j < i; j++ {
 			distances.set(i, j, entropy(frequencies[i][j], pop.Size()))
 		}
@@ -116,7 +186,7 @@ func distanceMatrix(pop *ga.Population, frequencies [][][]int) *matrix {
 		distances.set(i, i, entropy(frequencies[i][i], pop.Size()))
 	}
 
-	for i := 0; i < pop.Length(); i++ {
+	for i := 0; i < len(frequencies); i++ {
 		for j := 0; j < i; j++ {
 			distances.set(i, j, distances.get(i, i)+distances.get(j, j)-distances.get(i, j))
 		}
@@ -159,31 +229,53 @@ func mergeClusters(left, right []int) []int {
 	return dest
 }
 
-func LinkageTree(pop *ga.Population, frequencies [][][]int) [][]int {
+// Merge records a single step of the hierarchical clustering. Left and
+// Right index the two merged subsets in the FOS, Node indexes the subset
+// created by merging them, and Similarity is the mutual information
+// between the two subsets at the time of the merge.
+type Merge struct {
+	Left, Right, Node int
+	Similarity        float64
+}
+
+// Function LinkageTree builds the linkage tree of the population by
+// hierarchical clustering. Ties are broken randomly using r.
+func LinkageTree(pop *ga.Population, frequencies [][][]int, r *rand.Rand) [][]int {
+	fos, _ := LinkageTreeMerges(pop, frequencies, r)
+	return fos
+}
+
+// Function LinkageTreeMerges builds the linkage tree like LinkageTree, but
+// also returns the merges in the order in which they were performed.
+func LinkageTreeMerges(pop *ga.Population, frequencies [][][]int, r *rand.Rand) ([][]int, []Merge) {
+	return clusterMatrix(distanceMatrix(pop, frequencies), r)
+}
+
+// Function clusterMatrix builds the linkage tree by hierarchical clustering
+// of the problem variables, joining the variables with the highest value in
+// the similarity matrix first. The problem variables are the bits, the
+// categorical variables whose frequencies were counted, or the random keys
+// of a permutation.
+func clusterMatrix(distances *matrix, r *rand.Rand) ([][]int, []Merge) {
+
+	length := len(distances.data)
 
 	// Validate Input
 
-	switch pop.Length() {
+	switch length {
 	case 0:
-		return nil
+		return nil, nil
 	case 1:
-		return [][]int{[]int{0}}
+		return [][]int{[]int{0}}, nil
 	case 2:
-		return [][]int{[]int{0}, []int{1}, []int{0, 1}}
-	}
-	if pop.Length() == 0 {
-		return nil
+		merges := []Merge{{0, 1, 2, distances.get(0, 1)}}
+		return [][]int{[]int{0}, []int{1}, []int{0, 1}}, merges
 	}
 
-	if pop.Length() == 1 {
-	}
-
-	distances := distanceMatrix(pop, frequencies)
-
 	// Array mpm will store all unmerged subsets, starting from the
 	// singleton subsets and ending with the set of all problem variables.
-	mpm := make([][]int, pop.Length())
-	order := rand.Perm(pop.Length())
+	mpm := make([][]int, length)
+	order := r.Perm(length)
 	for i := 0; i < len(mpm); i++ {
 		mpm[i] = make([]int, 1)
 		mpm[i][0] = order[i]
@@ -191,14 +283,22 @@ func LinkageTree(pop *ga.Population, frequencies [][][]int) [][]int {
 
 	// Array fos will store all singleton subsets and every subset created
 	// by merging subsets during the algorithm.
-	fos := make([][]int, pop.Length(), pop.Length()+pop.Length()-1)
+	fos := make([][]int, length, length+length-1)
 	for i := 0; i < len(mpm); i++ {
 		fos[i] = mpm[i]
 	}
 
+	// Array nodes maps every subset in mpm to its index in fos, and merges
+	// records the order in which the subsets were merged.
+	nodes := make([]int, len(mpm))
+	for i := 0; i < len(nodes); i++ {
+		nodes[i] = i
+	}
+	merges := make([]Merge, 0, length-1)
+
 	// Similarites contains the similarity measures between the subsets
 	// stored in the mpm array.
-	sm := newMatrix(pop.Length())
+	sm := newMatrix(length)
 	for i := 0; i < len(mpm); i++ {
 		for j := 0; j < len(mpm); j++ {
 			sm.set(i, j, distances.get(mpm[i][0], mpm[j][0]))
@@ -206,14 +306,14 @@ func LinkageTree(pop *ga.Population, frequencies [][][]int) [][]int {
 		sm.set(i, i, 0.0)
 	}
 
-	chain := make([]int, pop.Length()+2)
+	chain := make([]int, length+2)
 	end := 0
 	done := false
 
 	for !done {
 		// Chain is empty, so pick a random subset from mpm as the start.
 		if end == 0 {
-			chain[end] = rand.Intn(len(mpm))
+			chain[end] = r.Intn(len(mpm))
 			end++
 		}
 
@@ -233,7 +333,7 @@ func LinkageTree(pop *ga.Population, frequencies [][][]int) [][]int {
 				chain[end] = chain[end-2]
 			}
 			end++
-			if end > pop.Length() {
+			if end > length {
 				break
 			}
 		}
@@ -251,6 +351,7 @@ func LinkageTree(pop *ga.Population, frequencies [][][]int) [][]int {
 			subset := mergeClusters(mpm[r0], mpm[r1])
 
 			fos = append(fos, subset)
+			merges = append(merges, Merge{nodes[r0], nodes[r1], len(fos) - 1, sm.get(r0, r1)})
 
 			sum := float64(len(mpm[r0]) + len(mpm[r1]))
 			mul0, mul1 := float64(len(mpm[r0]))/sum, float64(len(mpm[r1]))/sum
@@ -265,10 +366,12 @@ func LinkageTree(pop *ga.Population, frequencies [][][]int) [][]int {
 
 			// Subset r0 is replaced by the merged subset.
 			mpm[r0] = subset
+			nodes[r0] = len(fos) - 1
 
 			// Subset r1 is removed unless it was at the end.
 			if r1 < len(mpm)-1 {
 				mpm[r1] = mpm[len(mpm)-1]
+				nodes[r1] = nodes[len(mpm)-1]
 
 				for i := 0; i < r1; i++ {
 					sm.set(i, r1, sm.get(i, len(mpm)-1))
@@ -296,5 +399,5 @@ func LinkageTree(pop *ga.Population, frequencies [][][]int) [][]int {
 		}
 	}
 
-	return fos
+	return fos, merges
 }
@@ -3,66 +3,70 @@ package main
 import (
 	"fmt"
 	"github.com/Morenim/gom-opencl/bitset"
+	"github.com/Morenim/gom-opencl/ga"
+	"github.com/Morenim/gom-opencl/problem"
+	"math"
+	"math/rand"
 	"sort"
 	"testing"
 )
 
-func IntArrayEquals(a []int, b []int) bool {
-	if len(a) != len(b) {
-		return false
-	}
-	for i, v := range a {
-		if v != b[i] {
-			return false
-		}
-	}
-	return true
-}
-
 func first(bits bitset.BitSet, err error) bitset.BitSet {
 	return bits
 }
 
-var deceptivePopulation = Population{
-	Solutions: []Solution{
-		Solution{Fitness: 10, Bits: first(bitset.FromString("1111000011110000"))},
-		Solution{Fitness: 10, Bits: first(bitset.FromString("0000111111110000"))},
-		Solution{Fitness: 10, Bits: first(bitset.FromString("1111000011111111"))},
-		Solution{Fitness: 10, Bits: first(bitset.FromString("1111000011110000"))},
-		Solution{Fitness: 10, Bits: first(bitset.FromString("0000000000001111"))},
-		Solution{Fitness: 10, Bits: first(bitset.FromString("0000111111110000"))},
-		Solution{Fitness: 10, Bits: first(bitset.FromString("1111111100000000"))},
-		Solution{Fitness: 10, Bits: first(bitset.FromString("0000111100001111"))},
+var deceptivePopulation = ga.Population{
+	Solutions: []ga.Solution{
+		ga.Solution{Fitness: 10, Bits: first(bitset.FromString("1111000011110000"))},
+		ga.Solution{Fitness: 10, Bits: first(bitset.FromString("0000111111110000"))},
+		ga.Solution{Fitness: 10, Bits: first(bitset.FromString("1111000011111111"))},
+		ga.Solution{Fitness: 10, Bits: first(bitset.FromString("1111000011110000"))},
+		ga.Solution{Fitness: 10, Bits: first(bitset.FromString("0000000000001111"))},
+		ga.Solution{Fitness: 10, Bits: first(bitset.FromString("0000111111110000"))},
+		ga.Solution{Fitness: 10, Bits: first(bitset.FromString("1111111100000000"))},
+		ga.Solution{Fitness: 10, Bits: first(bitset.FromString("0000111100001111"))},
 	},
 }
 
-var deceptiveFOS = [][]int{
-	[]int{0, 1, 2, 3},
-	[]int{4, 5, 6, 7},
-	[]int{8, 9, 10, 11},
-	[]int{12, 13, 14, 15},
-}
-
 func TestDeceptiveLinkage(t *testing.T) {
 	freqs := Frequencies(&deceptivePopulation)
-	lt := LinkageTree(&deceptivePopulation, freqs)
+	lt := LinkageTree(&deceptivePopulation, freqs, rand.New(rand.NewSource(1)))
 
-	for i := 0; i < len(lt); i++ {
-		sort.Ints(lt[i])
-	}
+	blocks := problem.DeceptiveTrap(4).Blocks(deceptivePopulation.Length())
+	lq := linkageQuality(lt, blocks, deceptivePopulation.Length())
 
-	for _, dt := range deceptiveFOS {
-		found := false
+	if lq.Found != len(blocks) {
+		t.Logf("Linkage Tree:\n %v", lt)
+		t.Errorf("Deceptive traps not detected: %v.", lq)
+	}
+}
 
-		for _, subset := range lt {
-			if IntArrayEquals(subset, dt) {
-				found = true
-			}
-		}
+var linkageQualityTests = []struct {
+	fos      [][]int
+	blocks   [][]int
+	found    int
+	spurious int
+}{
+	// Both traps learned, then merged with each other.
+	{[][]int{{0}, {1}, {2}, {3}, {0, 1}, {2, 3}, {0, 1, 2, 3}},
+		[][]int{{0, 1}, {2, 3}}, 2, 0},
+	// Variables of different traps merged first.
+	{[][]int{{0}, {1}, {2}, {3}, {1, 2}, {0, 1, 2}, {0, 1, 2, 3}},
+		[][]int{{0, 1}, {2, 3}}, 0, 2},
+	// A complete trap merged with part of another trap.
+	{[][]int{{0}, {1}, {2}, {3}, {0, 1}, {0, 1, 2}, {0, 1, 2, 3}},
+		[][]int{{0, 1}, {2, 3}}, 1, 1},
+	// Hierarchical blocks are nested, so every merge is within a block.
+	{[][]int{{0}, {1}, {2}, {3}, {0, 1}, {2, 3}, {0, 1, 2, 3}},
+		problem.HIFF(0).Blocks(4), 3, 0},
+}
 
-		if !found {
-			t.Logf("Linkage Tree:\n %v", lt)
-			t.Errorf("Deceptive trap not detected.")
+func TestLinkageQuality(t *testing.T) {
+	for _, test := range linkageQualityTests {
+		lq := linkageQuality(test.fos, test.blocks, 4)
+		if lq.Found != test.found || lq.Spurious != test.spurious {
+			t.Errorf("linkageQuality(%v, %v) = %v, expected %d found and %d spurious.",
+				test.fos, test.blocks, lq, test.found, test.spurious)
 		}
 	}
 }
@@ -72,9 +76,10 @@ func TestDeceptiveLinkage(t *testing.T) {
 func TestHierarchicalStructure(t *testing.T) {
 
 	for i := 1; i < 32; i++ {
-		pop := NewPopulation(32, i)
+		r := rand.New(rand.NewSource(int64(i)))
+		pop := ga.NewPopulation(32, i, r)
 		freqs := Frequencies(pop)
-		lt := LinkageTree(pop, freqs)
+		lt := LinkageTree(pop, freqs, r)
 
 		expected := 2*i - 1
 		if len(lt) != expected {
@@ -89,8 +94,6 @@ func TestHierarchicalStructure(t *testing.T) {
 			numSingletons++
 		}
 
-		fmt.Sprintf("")
-
 		if numSingletons != i {
 			t.Errorf("FOS contained %d singleton subsets, expected %d", numSingletons, i)
 		}
@@ -159,3 +162,46 @@ func TestHierarchicalStructure(t *testing.T) {
 		}
 	}
 }
+
+func TestCategoricalFrequenciesBinary(t *testing.T) {
+	expected := Frequencies(&deceptivePopulation)
+	actual := CategoricalFrequencies(&deceptivePopulation, ga.Categorical{Alphabet: 2})
+
+	if fmt.Sprint(actual) != fmt.Sprint(expected) {
+		t.Errorf("CategoricalFrequencies with a binary alphabet = %v, expected %v.", actual, expected)
+	}
+}
+
+func TestCategoricalFrequencySparse(t *testing.T) {
+	c := ga.Categorical{Alphabet: 256}
+	r := rand.New(rand.NewSource(1))
+	pop, _ := ga.InitPopulation(50, 4*c.Width(), c, r)
+
+	// A pair of variables has 65536 joint values, of which at most 50 are
+	// observed.
+	counts := categoricalFrequency(pop, c, []int{0, 1})
+	if len(counts) > pop.Size() {
+		t.Errorf("categoricalFrequency() counted %d joint values of %d solutions.", len(counts), pop.Size())
+	}
+
+	sum := 0
+	for _, count := range counts {
+		sum += count
+	}
+	if sum != pop.Size() {
+		t.Errorf("categoricalFrequency() counted %d solutions, expected %d.", sum, pop.Size())
+	}
+}
+
+func TestCategoricalFrequencyOutsideAlphabet(t *testing.T) {
+	// Two variables of an alphabet of 3 hold the value 3 in their fields.
+	c := ga.Categorical{Alphabet: 3}
+	pop := &ga.Population{Solutions: []ga.Solution{
+		{Bits: first(bitset.FromString("1111"))},
+		{Bits: first(bitset.FromString("0000"))},
+	}}
+
+	if actual, expected := entropy(categoricalFrequency(pop, c, []int{0, 1}), pop.Size()), math.Log(2); math.Abs(actual-expected) > 1e-12 {
+		t.Errorf("Entropy of the joint values = %v, expected %v.", actual, expected)
+	}
+}
@@ -3,14 +3,17 @@ package main
 import (
 	"flag"
 	"fmt"
-	"github.com/Morenim/gom-opencl/bitset"
 	"github.com/Morenim/gom-opencl/ga"
 	"github.com/Morenim/gom-opencl/problem"
 	"github.com/rainliu/gocl/cl"
-	"io/ioutil"
+	"io"
 	"log"
+	"math"
 	"math/rand"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 	"unsafe"
 )
@@ -18,21 +21,112 @@ import (
 var (
 	useCPU         bool
 	printProblems  bool
+	platformIndex  int
+	deviceIndex    int
+	deviceName     string
+	deviceList     string
+	numWorkers     int
+	numIslands     int
+	noKernelCache  bool
+	kernelDir      string
+	problemKernel  string
+	problemEval    string
+	problemCommand string
+	problemAddress string
+	external       problem.ExternalOptions
+	verifySamples  int
+	cacheSize      int
+	fitnessCache   *problem.Cache
+	multiObjective bool
+	genotype       ga.Categorical
+	permutation    bool
+	realValued     bool
+	realLower      float64
+	realUpper      float64
+	valueToReach   float64
+	flowShopFile   string
+	machines       int
+	moClusters     int
+	frontFile      string
+	migrationFlags struct {
+		interval, count, degree          int
+		topology, selection, replacement string
+	}
 	verbosity      int
 	randomSeed     int
 	populationSize int
 	numGenerations int
 	problemLength  int
 	problemIndex   int
+	dumpInterval   int
+	dumpFormat     string
+	dumpPrefix     string
+	residentMode   bool
+	initMethod     string
+	initFile       string
+	backend        string
+	algorithm      string
+	tournamentSize int
+	crossoverName  string
+	mutationRate   float64
+	fitnessName    string
+	tolerance      float64
 )
 
-var problems = []struct {
+// A problemEntry is an optimization problem with its Go evaluator and the
+// kernel file defining evaluate() on the compute device.
+type problemEntry struct {
 	name      string
 	evaluator problem.Problem
 	clSource  string
-}{
+}
+
+var problems = []problemEntry{
 	{"Deceptive Trap", problem.DeceptiveTrap(4), "deceptive_trap.cl"},
 	{"HIFF", problem.HIFF(0), "hiff.cl"},
+
+	// The knapsack instance depends on the problem length and the k-ary
+	// trap on the alphabet, so they are created by parameterizedProblems
+	// once the command line is parsed.
+	{"0/1 Knapsack", nil, "knapsack.cl"},
+	{"k-ary Deceptive Trap", nil, "kary_trap.cl"},
+}
+
+// The indices of the parameterized problems in problems.
+const (
+	knapsackIndex = 2
+	karyTrapIndex = 3
+)
+
+// The multi-objective problems are created for a given problem length.
+var moProblems = []struct {
+	name   string
+	create func(length int) problem.MultiObjectiveProblem
+}{
+	{"Trap vs. Inverse Trap", func(length int) problem.MultiObjectiveProblem {
+		return problem.TrapInverseTrap(4)
+	}},
+	{"Knapsack Profit vs. Weight", func(length int) problem.MultiObjectiveProblem {
+		return problem.RandomKnapsack(length, rand.New(rand.NewSource(1)))
+	}},
+}
+
+// The real-valued problems are created for a given problem length, with
+// the kernel file defining objective() on the compute device.
+var realProblems = []struct {
+	name     string
+	create   func(length int) problem.RealProblem
+	clSource string
+}{
+	{"Sphere", func(length int) problem.RealProblem {
+		return problem.Sphere{}
+	}, "sphere.cl"},
+	{"Rosenbrock", func(length int) problem.RealProblem {
+		return problem.Rosenbrock{}
+	}, "rosenbrock.cl"},
+	{"Rotated Ellipsoid", func(length int) problem.RealProblem {
+		return problem.NewRotatedEllipsoid(length)
+	}, "rotated_ellipsoid.cl"},
 }
 
 type byLength [][]int
@@ -55,52 +149,6 @@ func (bl byLength) Less(i, j int) bool {
 	return false
 }
 
-// Find the first device matching the device type from the list of platforms.
-func findDevice(platforms []cl.CL_platform_id, deviceType cl.CL_device_type) (platformID cl.CL_platform_id, deviceID cl.CL_device_id) {
-
-	// Search all platforms for the first device.
-	for _, platform := range platforms {
-
-		var numDevices cl.CL_uint
-
-		// Get the number of matching devices for the platform.
-		status := cl.CLGetDeviceIDs(platform, deviceType, 0, nil, &numDevices)
-
-		// Check for errors, continue to next platform if no matching device was found.
-		switch status {
-		case cl.CL_DEVICE_NOT_FOUND:
-			fallthrough
-		case cl.CL_SUCCESS:
-			if numDevices == 0 {
-				continue
-			}
-		default:
-			log.Printf("OpenCL failed with status code: %s", cl.ERROR_CODES_STRINGS[-status])
-			log.Fatalf("Fatal error: could not retrieve devices for platform %d", platform)
-		}
-
-		device := make([]cl.CL_device_id, 1)
-
-		// Select first device matching the device type.
-		status = cl.CLGetDeviceIDs(
-			platform,
-			cl.CL_DEVICE_TYPE_GPU,
-			1,
-			device,
-			nil)
-
-		if status != cl.CL_SUCCESS {
-			log.Fatalf("Fatal error: could not retrieve GPU device for platform %d", platform)
-		}
-
-		platformID = platform
-		deviceID = device[0]
-		break
-	}
-
-	return
-}
-
 func printPlatforms(platforms []cl.CL_platform_id) {
 
 	log.Printf("Debug: found %d platforms:", len(platforms))
@@ -177,7 +225,7 @@ func printGeneration(numGenerations int, pop *ga.Population) {
 	fmt.Printf("Generation %d\n", numGenerations)
 	fmt.Println("===============")
 	for i, solution := range pop.Solutions {
-		fitness, optimal := problems[problemIndex].evaluator.Evaluate(solution.Bits)
+		fitness, optimal := hostEvaluator().Evaluate(solution.Bits)
 		solution.Fitness = fitness
 		fmt.Printf("x_%-2d: %v %t\n", i, solution, optimal)
 	}
@@ -185,7 +233,17 @@ func printGeneration(numGenerations int, pop *ga.Population) {
 	fmt.Println()
 }
 
-func flattenIntoSlice(src [][]int, dest []cl.CL_uint) {
+// Function printStatistics logs the statistics of the population. The best
+// solution is chosen by constraint domination, so for a constrained problem
+// it is feasible if any solution is.
+func printStatistics(generation int, pop *ga.Population) {
+	best := pop.BestFeasible(hostEvaluator())
+	log.Printf("Generation %d: best %g (x_%d), mean %g, std %g, worst %g, distance %.2f, unique %d",
+		generation, pop.Solutions[best].Fitness, best, pop.MeanFitness(), pop.StdFitness(),
+		pop.WorstFitness(), pop.MeanDistance(), pop.Unique())
+}
+
+func flattenIntoSlice(src [][]int, dest []cl.CL_uint) int {
 
 	dest[0] = cl.CL_uint(len(src))
 	i := 1
@@ -214,56 +272,53 @@ func flattenIntoSlice(src [][]int, dest []cl.CL_uint) {
 
 		dest[start] = cl.CL_uint(numMasks)
 	}
+
+	return i
 }
 
-func populationToSlice(pop *ga.Population, dest []cl.CL_uint) {
+// Function flattenForest flattens the linkage trees of the islands into
+// dest, preceded by the offset of every flattened tree in dest.
+func flattenForest(trees [][][]int, dest []cl.CL_uint) {
+	i := len(trees)
+	for t, tree := range trees {
+		dest[t] = cl.CL_uint(i)
+		i += flattenIntoSlice(tree, dest[i:])
+	}
+}
 
-	destPtr := 0
-	length := pop.Length()
-	numBlocks := blocksPerSolution(pop)
+func populationToSlice(pop *ga.Population, dest []uint32) {
+	pop.PutUInt32s(dest)
+}
 
-	for _, solution := range pop.Solutions {
-		toCopy := length
+func sliceToPopulation(src []uint32, pop *ga.Population) bool {
 
-		for j := 0; j < numBlocks; j++ {
-			// Determine the number of bits to copy.
-			blockSize := 32
-			if toCopy < 32 {
-				blockSize = toCopy
-			}
-			toCopy -= blockSize
+	foundOptimal := false
 
-			// Copy the bits into 32-bit integer.
-			var raw uint32
-			raw = 0
+	pop.LoadUInt32s(src)
 
-			for k := 0; k < blockSize; k++ {
-				index := j*32 + k
-				if solution.Bits.Has(index) {
-					raw |= (1 << uint32(k))
-				}
-			}
-
-			dest[destPtr] = cl.CL_uint(raw)
-			destPtr++
+	for i := range pop.Solutions {
+		fitness, optimal := hostEvaluator().Evaluate(pop.Solutions[i].Bits)
+		pop.Solutions[i].Fitness = fitness
+		if optimal {
+			foundOptimal = true
 		}
 	}
+
+	return foundOptimal
 }
 
-func sliceToPopulation(src []cl.CL_uint, pop *ga.Population) bool {
+// Function residentToPopulation mirrors the device population on the host
+// for linkage learning. The fitness values are taken from the device, so
+// no solutions are evaluated on the host.
+func residentToPopulation(src []uint32, fitnesses []float64, pop *ga.Population, optimum float64) bool {
 
-	numBlocks := blocksPerSolution(pop)
-	buffer := make([]uint32, numBlocks)
 	foundOptimal := false
 
-	for i, _ := range pop.Solutions {
-		for j := 0; j < numBlocks; j++ {
-			buffer[j] = uint32(src[i*numBlocks+j])
-		}
-		pop.Solutions[i].Bits, _ = bitset.FromUInt32s(buffer, pop.Length())
-		fitness, optimal := problems[problemIndex].evaluator.Evaluate(pop.Solutions[i].Bits)
-		pop.Solutions[i].Fitness = fitness
-		if optimal {
+	pop.LoadUInt32s(src)
+
+	for i := range pop.Solutions {
+		pop.Solutions[i].Fitness = fitnesses[i]
+		if pop.Solutions[i].Fitness+tolerance >= optimum {
 			foundOptimal = true
 		}
 	}
@@ -299,9 +354,116 @@ func setKernelArg(kernel cl.CL_kernel, pos int, data interface{}) {
 func printProblemList() {
 	fmt.Println("Index 0: Deceptive Trap Function (k = 4)")
 	fmt.Println("Index 1: HIFF")
+	fmt.Println("Index 2: 0/1 Knapsack (constrained)")
+	fmt.Println("Index 3: k-ary Deceptive Trap Function (k = 4, see -alphabet)")
+
+	fmt.Println()
+	fmt.Println("Real-valued problems (-real):")
+	for i, p := range realProblems {
+		fmt.Printf("Index %d: %s\n", i, p.name)
+	}
+
+	fmt.Println()
+	fmt.Println("Permutation problems (-permutation):")
+	fmt.Println("  Permutation Flow-Shop (see -flowshop)")
+
+	fmt.Println()
+	fmt.Println("Multi-objective problems (-mo):")
+	for i, p := range moProblems {
+		fmt.Printf("Index %d: %s\n", i, p.name)
+	}
+
+	if names := problem.Registered(); len(names) > 0 {
+		fmt.Println()
+		fmt.Println("Registered evaluators:")
+		for _, name := range names {
+			fmt.Printf("  %s\n", name)
+		}
+	}
 	os.Exit(0)
 }
 
+// Function hostEvaluator returns the evaluator of the problem on the host,
+// memoised by the fitness cache if enabled.
+func hostEvaluator() problem.Problem {
+	if fitnessCache != nil {
+		return fitnessCache
+	}
+	return problems[problemIndex].evaluator
+}
+
+// Function parameterizedProblems creates the problems depending on the
+// command line. The knapsack instance of the problem length is drawn from a
+// fixed seed, so every run optimizes the same instance.
+func parameterizedProblems() {
+	problems[knapsackIndex].evaluator = problem.RandomKnapsack(problemLength, rand.New(rand.NewSource(1)))
+	problems[karyTrapIndex].evaluator = problem.KaryTrap{Alphabet: genotype.Alphabet, K: 4}
+}
+
+// Function customProblem adds the custom problem given on the command line
+// to the problems and selects it. Its evaluator is either registered in
+// the problem package at build time or an external program.
+func customProblem() {
+	if problemKernel == "" && problemEval == "" && problemCommand == "" && problemAddress == "" {
+		return
+	}
+
+	var evaluator problem.Problem
+	var name string
+
+	sources := 0
+	for _, source := range []string{problemEval, problemCommand, problemAddress} {
+		if source != "" {
+			sources++
+		}
+	}
+
+	switch {
+	case sources > 1:
+		log.Fatalf("Fatal error: -problem-evaluator, -problem-command and -problem-address are mutually exclusive.")
+
+	case problemEval != "":
+		p, ok := problem.Lookup(problemEval)
+		if !ok {
+			log.Fatalf("Fatal error: no evaluator is registered as %q, see -problem-list.", problemEval)
+		}
+		evaluator, name = p, problemEval
+
+	case problemCommand != "":
+		fields := strings.Fields(problemCommand)
+		if len(fields) == 0 {
+			log.Fatalf("Fatal error: the problem command is empty.")
+		}
+		p, err := problem.Command(external, fields[0], fields[1:]...)
+		if err != nil {
+			log.Fatalf("Fatal error: could not start the problem command: %v", err)
+		}
+		evaluator, name = p, fields[0]
+
+	case problemAddress != "":
+		network, address, ok := strings.Cut(problemAddress, ":")
+		if !ok || (network != "tcp" && network != "unix") {
+			log.Fatalf("Fatal error: invalid problem address %q, expected tcp:host:port or unix:path.", problemAddress)
+		}
+		p, err := problem.Dial(external, network, address)
+		if err != nil {
+			log.Fatalf("Fatal error: could not connect to the problem evaluator: %v", err)
+		}
+		evaluator, name = p, problemAddress
+
+	default:
+		log.Fatalf("Fatal error: -problem-kernel requires -problem-evaluator, -problem-command or -problem-address.")
+	}
+
+	clSource := ""
+	if problemKernel != "" {
+		clSource = filepath.Base(problemKernel)
+	}
+
+	problems = append(problems, problemEntry{name, evaluator, clSource})
+	problemIndex = len(problems) - 1
+}
+
 func printProgramInfo(program cl.CL_program, name cl.CL_program_info) string {
 
 	var buffer interface{}
@@ -344,6 +506,70 @@ func parseCommandLine() {
 
 	flag.BoolVar(&useCPU, "cpu", false, "Whether to use the CPU over the GPU.")
 
+	flag.IntVar(&platformIndex, "platform", -1, "Index of the OpenCL platform to use, as listed by the devices command. Defaults to any platform.")
+
+	flag.IntVar(&deviceIndex, "device", -1, "Index of the OpenCL device on the platform, as listed by the devices command. Overrides -cpu.")
+
+	flag.StringVar(&deviceName, "device-name", "", "Use the first device whose name contains this case-insensitive substring.")
+
+	flag.StringVar(&deviceList, "devices", "", "Comma-separated platform:device indices of several OpenCL devices sharing the population, e.g. 0:0,0:1. Overrides -device.")
+
+	flag.IntVar(&numWorkers, "workers", 1, "Number of goroutines sharing the population in the Go backend.")
+
+	flag.StringVar(&kernelDir, "kernel-dir", "", "Directory of OpenCL kernel sources overriding the embedded kernels with the same file name.")
+
+	flag.BoolVar(&noKernelCache, "no-kernel-cache", false, "Always build the OpenCL program from source, without reading or writing cached binaries.")
+
+	flag.StringVar(&backend, "backend", "opencl", "Backend performing GOMEA: opencl or go.")
+
+	flag.StringVar(&algorithm, "algorithm", "gomea", "Algorithm to perform: gomea, or the baselines ga and ecga on the host.")
+
+	flag.IntVar(&tournamentSize, "tournament", 0, "Tournament size of the baselines. Defaults to 2 for the GA and 4 for ECGA.")
+
+	flag.StringVar(&crossoverName, "crossover", "uniform", "Crossover of the GA: uniform or onepoint.")
+
+	flag.Float64Var(&mutationRate, "mutation", -1, "Bit-flip probability of the GA. Defaults to one over the solution length.")
+
+	flag.StringVar(&fitnessName, "fitness-type", "uint", "Type of the fitness values on the compute device: uint, int, float or double.")
+
+	flag.Float64Var(&tolerance, "tolerance", 0, "Tolerance of fitness comparisons for floating-point fitness values.")
+
+	flag.IntVar(&numIslands, "islands", 1, "Number of islands of equal size, each learning its own linkage tree.")
+
+	flag.IntVar(&migrationFlags.interval, "migration-interval", 10, "Number of generations between migrations among the islands. Disabled if 0.")
+
+	flag.StringVar(&migrationFlags.topology, "migration-topology", "ring", "Topology of the islands: ring, full or random.")
+
+	flag.IntVar(&migrationFlags.degree, "migration-degree", 1, "Number of islands receiving migrants from every island in the random topology.")
+
+	flag.IntVar(&migrationFlags.count, "migration-count", 1, "Number of solutions every island sends to each neighbour.")
+
+	flag.StringVar(&migrationFlags.selection, "migration-selection", "best", "Emigrants of an island: best or random.")
+
+	flag.StringVar(&migrationFlags.replacement, "migration-replacement", "worst", "Solutions replaced by immigrants: worst or random.")
+
+	flag.BoolVar(&multiObjective, "mo", false, "Perform MO-GOMEA on the multi-objective problem given by -index, see -problem-list.")
+
+	flag.IntVar(&moClusters, "mo-clusters", 5, "Number of clusters in objective space for MO-GOMEA.")
+
+	flag.StringVar(&frontFile, "front-file", "", "CSV file receiving the final Pareto front of MO-GOMEA.")
+
+	flag.BoolVar(&realValued, "real", false, "Perform real-valued GOMEA on the real-valued problem given by -index, see -problem-list.")
+
+	flag.Float64Var(&realLower, "lower", -5, "Lower bound of the initial values of real-valued variables.")
+
+	flag.Float64Var(&realUpper, "upper", 5, "Upper bound of the initial values of real-valued variables.")
+
+	flag.Float64Var(&valueToReach, "vtr", 1e-10, "Objective value at which real-valued GOMEA terminates.")
+
+	flag.BoolVar(&permutation, "permutation", false, "Perform Permutation GOMEA with random keys on the flow-shop problem.")
+
+	flag.StringVar(&flowShopFile, "flowshop", "", "Flow-shop instance in the format of Taillard. A random instance of -length jobs if empty.")
+
+	flag.IntVar(&machines, "machines", 5, "Number of machines of the random flow-shop instance.")
+
+	flag.IntVar(&cacheSize, "fitness-cache", 0, "Number of solutions whose fitness values are memoised on the host. Disabled if 0.")
+
 	flag.IntVar(&verbosity, "verbosity", 0, "Verbosity of the output.")
 
 	flag.IntVar(&randomSeed, "random", 0, "Random seed to use. Defaults to a time-based random seed.")
@@ -356,9 +582,157 @@ func parseCommandLine() {
 
 	flag.IntVar(&problemIndex, "index", 0, "Index of the optimization problem to solve.")
 
+	flag.StringVar(&problemKernel, "problem-kernel", "", "OpenCL source file of a custom problem defining evaluate(), which requires an evaluator on the host.")
+
+	flag.StringVar(&problemEval, "problem-evaluator", "", "Name of a registered Go evaluator of a custom problem, see -problem-list.")
+
+	flag.StringVar(&problemCommand, "problem-command", "", "Command line of an external program evaluating a custom problem.")
+
+	flag.StringVar(&problemAddress, "problem-address", "", "Address of an external evaluator of a custom problem, as tcp:host:port or unix:path.")
+
+	flag.StringVar(&external.Encoding, "external-encoding", "text", "Encoding of the solutions sent to an external evaluator: text, hex or base64.")
+
+	flag.BoolVar(&external.JSON, "external-json", false, "Use the JSON protocol for the external evaluator instead of the line protocol.")
+
+	flag.IntVar(&external.BatchSize, "external-batch", 1, "Maximum number of solutions sent to the external evaluator at once.")
+
+	flag.DurationVar(&external.BatchDelay, "external-delay", 0, "How long a batch for the external evaluator waits for further solutions.")
+
+	flag.DurationVar(&external.Timeout, "external-timeout", 0, "How long the external evaluator may take to reply to a batch. Disabled if 0.")
+
+	flag.IntVar(&external.Connections, "external-connections", 1, "Number of external evaluator processes or connections evaluating batches concurrently.")
+
+	flag.IntVar(&verifySamples, "verify-samples", 100, "Number of random solutions on which a user-supplied problem kernel must agree with its evaluator. Disabled if 0.")
+
 	flag.BoolVar(&printProblems, "problem-list", false, "Print a list of the available optimization problems and terminate.")
 
+	flag.IntVar(&dumpInterval, "dump-tree", 0, "Dump the linkage tree and distance matrix every N generations. Disabled if 0.")
+
+	flag.StringVar(&dumpFormat, "dump-format", "dot", "Format of the dumped linkage tree: dot or newick.")
+
+	flag.StringVar(&dumpPrefix, "dump-prefix", "linkage", "File name prefix of the dumped linkage trees and distance matrices.")
+
+	flag.BoolVar(&residentMode, "resident", false, "Keep the population on the compute device between generations.")
+
+	flag.IntVar(&genotype.Alphabet, "alphabet", 2, "Alphabet size of the problem variables, packed into bit fields if greater than 2 (at most 256).")
+
+	flag.StringVar(&initMethod, "init", "uniform", "Population initialization: uniform, balanced or hillclimb.")
+
+	flag.StringVar(&initFile, "init-file", "", "File of known solutions, one per line, seeding the initial population.")
+
 	flag.Parse()
+
+	if genotype.Alphabet < 2 || genotype.Alphabet > 256 {
+		log.Fatalf("Fatal error: the alphabet size must be between 2 and 256.")
+	}
+
+	// The other problems read the packed fields as plain bits.
+	custom := problemKernel != "" || problemEval != "" || problemCommand != "" || problemAddress != ""
+	karyTrap := problemIndex == karyTrapIndex && !custom && !multiObjective && !permutation && !realValued
+	if genotype.Alphabet > 2 && !karyTrap {
+		log.Fatalf("Fatal error: an alphabet size above 2 requires the k-ary trap (-index %d).", karyTrapIndex)
+	}
+
+	if dumpFormat != "dot" && dumpFormat != "newick" {
+		log.Fatalf("Fatal error: unknown linkage tree format %q.", dumpFormat)
+	}
+
+	if populationSize < 1 || numIslands < 1 {
+		log.Fatalf("Fatal error: the population size and the number of islands must be positive.")
+	}
+}
+
+// Function newInitializer returns the population initializer selected on
+// the command line.
+func newInitializer() ga.Initializer {
+	var init ga.Initializer
+
+	if genotype.Alphabet > 2 && initMethod != "uniform" {
+		log.Fatalf("Fatal error: categorical variables are only initialized uniformly.")
+	}
+
+	switch initMethod {
+	case "uniform":
+		init = ga.Uniform{}
+		if genotype.Alphabet > 2 {
+			init = genotype
+		}
+	case "balanced":
+		init = ga.Balanced{}
+	case "hillclimb":
+		init = ga.HillClimbed{Problem: hostEvaluator(), Base: ga.Uniform{}}
+	default:
+		log.Fatalf("Fatal error: unknown initialization method %q.", initMethod)
+	}
+
+	if initFile != "" {
+		file, err := os.Open(initFile)
+		if err != nil {
+			log.Fatalf("Fatal error: could not open the initialization file: %v", err)
+		}
+		defer file.Close()
+
+		solutions, err := ga.ReadSolutions(file)
+		if err != nil {
+			log.Fatalf("Fatal error: could not read the initialization file: %v", err)
+		}
+		init = ga.Seeded{Solutions: solutions, Rest: init, Genotype: genotype}
+	}
+
+	return init
+}
+
+// Function newMigration returns the migration policy selected on the
+// command line.
+func newMigration() ga.Migration {
+	m := ga.Migration{Count: migrationFlags.count, Problem: hostEvaluator()}
+
+	switch migrationFlags.topology {
+	case "ring":
+		m.Topology = ga.Ring{}
+	case "full":
+		m.Topology = ga.FullyConnected{}
+	case "random":
+		m.Topology = ga.RandomTopology{Degree: migrationFlags.degree}
+	default:
+		log.Fatalf("Fatal error: unknown migration topology %q.", migrationFlags.topology)
+	}
+
+	switch migrationFlags.selection {
+	case "best":
+		m.Selection = ga.SelectBest
+	case "random":
+		m.Selection = ga.SelectRandom
+	default:
+		log.Fatalf("Fatal error: unknown migration selection %q.", migrationFlags.selection)
+	}
+
+	switch migrationFlags.replacement {
+	case "worst":
+		m.Replacement = ga.ReplaceWorst
+	case "random":
+		m.Replacement = ga.ReplaceRandom
+	default:
+		log.Fatalf("Fatal error: unknown migration replacement %q.", migrationFlags.replacement)
+	}
+
+	return m
+}
+
+// Function splitIslands splits the population into the islands selected on
+// the command line.
+func splitIslands(pop *ga.Population) []*ga.Population {
+	islands, err := pop.Split(numIslands)
+	if err != nil {
+		log.Fatalf("Fatal error: %v", err)
+	}
+	return islands
+}
+
+// Function migrating tests whether the islands exchange solutions after
+// the generation.
+func migrating(generationsPassed int) bool {
+	return numIslands > 1 && migrationFlags.interval > 0 && generationsPassed%migrationFlags.interval == 0
 }
 
 func blocksPerSolution(pop *ga.Population) int {
@@ -372,22 +746,13 @@ func requireSuccess(status cl.CL_int, customError string) {
 	}
 }
 
-func runOpenCL() {
-
-	var status cl.CL_int
-
-	var numPlatforms cl.CL_uint
+func runOpenCL(pop *ga.Population, rng *rand.Rand) {
 
 	//---------------------------------------------------
 	// Step 1: Discover and retrieve OpenCL platforms.
 	//---------------------------------------------------
 
-	status = cl.CLGetPlatformIDs(0, nil, &numPlatforms)
-
-	platforms := make([]cl.CL_platform_id, numPlatforms)
-
-	requireSuccess(cl.CLGetPlatformIDs(numPlatforms, platforms, nil),
-		"could not retrieve OpenCL platform IDs.")
+	platforms := getPlatforms()
 
 	if verbosity >= 4 {
 		printPlatforms(platforms)
@@ -405,231 +770,623 @@ func runOpenCL() {
 		preferredType = cl.CL_DEVICE_TYPE_GPU
 	}
 
-	_, gpuDevice := findDevice(platforms, preferredType)
-	gpuDevices := make([]cl.CL_device_id, 1)
-	gpuDevices[0] = gpuDevice
+	devices := selectDevices(platforms, preferredType)
 
-	if verbosity >= 4 {
-		printDeviceInfo(gpuDevice)
+	// A resident population only lives on a single device.
+	if residentMode && len(devices) > 1 {
+		log.Fatalf("Fatal error: resident mode requires a single device.")
 	}
 
 	//---------------------------------------------------
-	// Step 3: Create an OpenCL context.
+	// Step 3: Read the OpenCL program sources.
 	//---------------------------------------------------
 
-	context := cl.CLCreateContext(nil, 1, gpuDevices, nil, nil, &status)
-	requireSuccess(status, "could not create OpenCL context.")
-	defer cl.CLReleaseContext(context)
+	if problems[problemIndex].clSource == "" {
+		log.Fatalf("Fatal error: the problem has no kernel, use -problem-kernel or the Go backend.")
+	}
+
+	clSourceData, userKernel, err := kernelSources(problems[problemIndex].clSource)
+	if err != nil {
+		log.Fatalf("Fatal error: %v", err)
+	}
+
+	clSourceData[1] = append(categoricalDefinitions(genotype), clSourceData[1]...)
+	if knapsack, ok := problems[problemIndex].evaluator.(*problem.Knapsack); ok {
+		clSourceData[1] = append(knapsackDefinitions(knapsack), clSourceData[1]...)
+	}
 
 	//---------------------------------------------------
-	// Step 3: Create an OpenCL command queue.
+	// Step 4: Create a context, command queue, kernel
+	// and memory buffers for every device.
 	//---------------------------------------------------
 
-	commandQueue := cl.CLCreateCommandQueue(context, gpuDevice, 0, &status)
-	requireSuccess(status, "could not create OpenCL command queue.")
-	defer cl.CLReleaseCommandQueue(commandQueue)
+	fitness := findFitnessType(fitnessName)
+
+	computeDevices := make([]*computeDevice, len(devices))
+
+	for i, device := range devices {
+		computeDevices[i] = newComputeDevice(device, clSourceData, fitness, pop.Size(), pop.Length(), numIslands)
+		defer computeDevices[i].release()
+
+		if userKernel {
+			verifyProblemKernel(computeDevices[i], problems[problemIndex].evaluator, fitness, verifySamples, pop.Length(), rng)
+		}
+	}
 
 	//---------------------------------------------------
-	// Step 4: Create OpenCL program and kernel.
+	// Step 5: Initialize host memory.
 	//---------------------------------------------------
 
-	var clSourceData [3][]byte
-	var clSourceLengths [3]cl.CL_size_t
-	var err error
+	numBlocks := blocksPerSolution(pop) * pop.Size()
+	dataSize := populationBytes(pop.Size(), pop.Length())
+	ltSize := linkageTreeBytes(problemLength, numIslands)
 
-	clSourceFiles := []string{
-		"kernels/" + problems[problemIndex].clSource,
-		"kernels/rng.cl",
-		"kernels/gom.cl",
+	populationData := make([]uint32, numBlocks)
+	offspringData := make([]uint32, numBlocks)
+	ltData := make([]cl.CL_uint, ltSize/4)
+	improvsData := make([]cl.CL_char, pop.Size())
+	fitnessData := make([]byte, fitness.size*pop.Size())
+	fitnessValues := make([]float64, pop.Size())
+
+	// Problems without a known optimum are only checked on the host.
+	optimum := math.Inf(1)
+	if bounded, ok := problems[problemIndex].evaluator.(problem.Bounded); ok {
+		optimum = bounded.Optimum(problemLength)
+	} else if residentMode {
+		log.Fatalf("Fatal error: resident mode requires a problem with a known optimum.")
 	}
 
-	for i, s := range clSourceFiles {
-		clSourceData[i], err = ioutil.ReadFile(s)
+	//---------------------------------------------------
+	// Step 6: Perform GOMEA.
+	//---------------------------------------------------
+
+	done := false
 
-		if err != nil {
-			log.Fatalf("Could not read the kernel source file %s.", s)
+	generationsPassed := 0
+
+	throughput := make([]float64, len(computeDevices))
+
+	islands := splitIslands(pop)
+	islandSize := pop.Size() / numIslands
+	migration := newMigration()
+	trees := make([][][]int, numIslands)
+
+	// Whether the population on the devices is out of date, which in
+	// resident mode only happens initially and after migrations.
+	upload := true
+
+	if verbosity >= 3 {
+		printGeneration(0, pop)
+	}
+
+	for !done {
+
+		// Build the linkage tree of every island and flatten them for the
+		// compute devices, masking all bits of every categorical variable.
+		for i, island := range islands {
+			var freqs [][][]int
+			if genotype.Alphabet > 2 {
+				freqs = CategoricalFrequencies(island, genotype)
+			} else {
+				freqs = Frequencies(island)
+			}
+			lt, merges := LinkageTreeMerges(island, freqs, rng)
+			trees[i] = genotype.Expand(lt)
+
+			reportLinkage(generationsPassed, i, island, freqs, lt, merges)
 		}
+		flattenForest(trees, ltData)
 
-		clSourceLengths[i] = cl.CL_size_t(len(clSourceData[i]))
+		// Every device holds the complete population as the donor pool.
+		upload = upload || !residentMode
+		if upload {
+			populationToSlice(pop, populationData)
+		}
+
+		// Split the population into slices balanced by the throughput of
+		// the devices in the previous generation.
+		for i, cd := range computeDevices {
+			throughput[i] = cd.throughput
+		}
+
+		offset := 0
+
+		for i, count := range balance(pop.Size(), throughput) {
+			computeDevices[i].offset, computeDevices[i].count = offset, count
+			offset += count
+		}
+
+		// Perform GOM crossover on all devices at once. The devices write
+		// disjoint slices of the host arrays.
+		var wg sync.WaitGroup
+
+		for _, cd := range computeDevices {
+			wg.Add(1)
+			go func(cd *computeDevice) {
+				defer wg.Done()
+
+				cd.write(cd.lt, ltSize, unsafe.Pointer(&ltData[0]))
+
+				if upload {
+					cd.write(cd.population, dataSize, unsafe.Pointer(&populationData[0]))
+				}
+
+				cd.mix(pop.Size(), pop.Length(), islandSize, fitness, offspringData, improvsData, fitnessData)
+			}(cd)
+		}
+
+		wg.Wait()
+
+		upload = false
+
+		if verbosity >= 4 {
+			for i, cd := range computeDevices {
+				log.Printf("Device %d mixed %d solutions at %.0f solutions/s.", i, cd.count, cd.throughput)
+			}
+		}
+
+		var foundOptimal bool
+
+		if residentMode {
+			fitness.decodeAll(fitnessData, fitnessValues)
+			foundOptimal = residentToPopulation(offspringData, fitnessValues, pop, optimum)
+
+			// The offspring become the parents of the next generation.
+			cd := computeDevices[0]
+			cd.population, cd.offspring = cd.offspring, cd.population
+		} else {
+			foundOptimal = sliceToPopulation(offspringData, pop)
+		}
+
+		generationsPassed++
+
+		improved := false
+
+		for _, b := range improvsData {
+			if b > 0 {
+				improved = true
+				break
+			}
+		}
+
+		// The islands share their solutions with the population, so
+		// migrants replace solutions of the population as well.
+		if migrating(generationsPassed) {
+			ga.Migrate(islands, migration, rng)
+			upload = true
+		}
+
+		done = endGeneration(generationsPassed, pop, improved, foundOptimal)
 	}
+}
 
-	program := cl.CLCreateProgramWithSource(context, 3, clSourceData[:], clSourceLengths[:], &status)
-	requireSuccess(status, "could not compile an OpenCL kernel from source.")
+// Function runGo performs GOMEA on the host with the Go backend. Every
+// island is optimized independently with its own random source.
+func runGo(pop *ga.Population, rng *rand.Rand) {
 
-	status = cl.CLBuildProgram(program, 1, gpuDevices, nil, nil, nil)
+	islands := splitIslands(pop)
+	migration := newMigration()
+	optimizers := make([]*Optimizer, len(islands))
 
-	if status != cl.CL_SUCCESS {
-		printProgramBuildInfo(program, gpuDevice)
+	for i, island := range islands {
+		r := rng
+		if len(islands) > 1 {
+			r = rand.New(rand.NewSource(rng.Int63()))
+		}
+
+		optimizers[i] = NewOptimizer(hostEvaluator(), island, r)
+		optimizers[i].Tolerance = tolerance
+		optimizers[i].Workers = numWorkers
+		optimizers[i].Genotype = genotype
 	}
 
-	kernel := cl.CLCreateKernel(program, []byte("gom"), &status)
-	requireSuccess(status, "could not create OpenCL kernel.")
+	if verbosity >= 3 {
+		printGeneration(0, pop)
+	}
 
-	//---------------------------------------------------
-	// Step 6: Initialize OpenCL memory.
-	//---------------------------------------------------
+	done := false
 
-	if verbosity >= 4 {
-		printKernelWorkGroup(kernel, gpuDevice)
+	for generationsPassed := 0; !done; {
+		improved, foundOptimal := false, false
+
+		for i, optimizer := range optimizers {
+			optimizer.Learn()
+			reportLinkage(generationsPassed, i, islands[i], optimizer.Frequencies, optimizer.FOS, optimizer.Merges)
+
+			better, optimal := optimizer.Mix()
+			improved, foundOptimal = improved || better, foundOptimal || optimal
+		}
+
+		generationsPassed++
+
+		if migrating(generationsPassed) {
+			for i, replaced := range ga.Migrate(islands, migration, rng) {
+				optimizers[i].Replaced(replaced)
+			}
+		}
+
+		// The optimizers replace the solution slices of the islands, so
+		// the population is joined anew.
+		ga.Join(islands, pop)
+
+		done = endGeneration(generationsPassed, pop, improved, foundOptimal)
 	}
+}
 
-	//---------------------------------------------------
-	// Step 7: Initialize OpenCL memory.
-	//---------------------------------------------------
+// Function runMO performs MO-GOMEA on the host and exports the final
+// Pareto front.
+func runMO(pop *ga.Population, rng *rand.Rand) {
 
-	var size cl.CL_uint
-	length := cl.CL_size_t(problemLength)
+	if backend != "go" {
+		log.Fatalf("Fatal error: MO-GOMEA requires the Go backend.")
+	}
+	if problemIndex < 0 || problemIndex >= len(moProblems) {
+		log.Fatalf("Fatal error: unknown multi-objective problem %d, see -problem-list.", problemIndex)
+	}
 
-	pop := ga.NewPopulation(populationSize, problemLength)
+	p := moProblems[problemIndex].create(problemLength)
+	optimizer := NewMOOptimizer(p, pop, moClusters, rng)
 
-	numBlocks := blocksPerSolution(pop) * pop.Size()
-	dataSize := cl.CL_size_t(unsafe.Sizeof(size)) * cl.CL_size_t(numBlocks)
+	done := false
+	window := stallWindow{limit: 1}
 
-	populationData := make([]cl.CL_uint, numBlocks)
-	offspringData := make([]cl.CL_uint, numBlocks)
+	for generationsPassed := 0; !done; {
+		improved := optimizer.Step()
+		generationsPassed++
 
-	populationBuffer := cl.CLCreateBuffer(
-		context, cl.CL_MEM_READ_ONLY, dataSize, nil, &status)
-	requireSuccess(status, "could not allocate an OpenCL memory buffer.")
+		done = terminated(generationsPassed, window.update(improved), false, false)
 
-	cloneBuffer := cl.CLCreateBuffer(
-		context, cl.CL_MEM_READ_WRITE, dataSize, nil, &status)
-	requireSuccess(status, "could not allocate an OpenCL memory buffer.")
+		if verbosity >= 2 {
+			if p.Objectives() == 2 {
+				log.Printf("Generation %d: archive %d solutions, hypervolume %g",
+					generationsPassed, len(optimizer.Archive.Objectives), optimizer.Hypervolume(pop.Length()))
+			} else {
+				log.Printf("Generation %d: archive %d solutions", generationsPassed, len(optimizer.Archive.Objectives))
+			}
+		}
+	}
 
-	// Maximum bound on the number of elements in the LT + node sizes.
-	boundSum := (length*length+3*length-2)/2 + (2*length - 1) + 1
-	ltSize := cl.CL_size_t(unsafe.Sizeof(length)) * boundSum
+	if frontFile != "" {
+		file, err := os.Create(frontFile)
+		if err != nil {
+			log.Fatalf("Fatal error: could not create the front file: %v", err)
+		}
+		defer file.Close()
 
-	ltData := make([]cl.CL_uint, boundSum)
+		if err := writeFront(file, &optimizer.Archive); err != nil {
+			log.Fatalf("Fatal error: could not write the front file: %v", err)
+		}
+	} else if verbosity >= 1 {
+		writeFront(os.Stdout, &optimizer.Archive)
+	}
+}
 
-	ltBuffer := cl.CLCreateBuffer(context, cl.CL_MEM_READ_ONLY, ltSize, nil, &status)
-	requireSuccess(status, "could not allocate an OpenCL memory buffer.")
+// Function flowShop returns the flow-shop instance given on the command
+// line, or a random instance drawn from a fixed seed.
+func flowShop() *problem.FlowShop {
+	if flowShopFile == "" {
+		return problem.RandomFlowShop(problemLength, machines, rand.New(rand.NewSource(1)))
+	}
 
-	var dummyCLBool cl.CL_char
-	improvsSize := cl.CL_size_t(unsafe.Sizeof(dummyCLBool)) * cl.CL_size_t(pop.Size())
-	improvsData := make([]cl.CL_char, pop.Size())
-	improvsBuffer := cl.CLCreateBuffer(context, cl.CL_MEM_WRITE_ONLY, improvsSize, nil, &status)
-	requireSuccess(status, "could not allocate an OpenCL memory buffer.")
+	file, err := os.Open(flowShopFile)
+	if err != nil {
+		log.Fatalf("Fatal error: could not open the flow-shop instance: %v", err)
+	}
+	defer file.Close()
 
-	offspringBuffer := cl.CLCreateBuffer(
-		context, cl.CL_MEM_WRITE_ONLY, dataSize, nil, &status)
-	requireSuccess(status, "could not allocate an OpenCL memory buffer.")
+	f, err := problem.ReadFlowShop(file)
+	if err != nil {
+		log.Fatalf("Fatal error: could not read the flow-shop instance: %v", err)
+	}
+	return f
+}
 
-	//---------------------------------------------------
-	// Step 8: Perform GOMEA.
-	//---------------------------------------------------
+// Function runPermutation performs Permutation GOMEA on the host and
+// reports the best schedule found.
+func runPermutation(rng *rand.Rand) {
 
-	if randomSeed == 0 {
-		rand.Seed(time.Now().Unix())
-	} else {
-		rand.Seed(int64(randomSeed))
+	if backend != "go" {
+		log.Fatalf("Fatal error: Permutation GOMEA requires the Go backend.")
 	}
 
+	f := flowShop()
+	pop := ga.NewKeyPopulation(populationSize, f.Jobs(), rng)
+	optimizer := NewPermutationOptimizer(f, pop, rng)
+
 	done := false
 
-	generationsPassed := 0
+	window := stallWindow{limit: 1}
+
+	for generationsPassed := 0; !done; {
+		improved, foundOptimal := optimizer.Step()
+		generationsPassed++
+
+		done = terminated(generationsPassed, window.update(improved), pop.Converged(), foundOptimal)
+
+		if verbosity >= 2 {
+			log.Printf("Generation %d: best makespan %g, mean %g",
+				generationsPassed, -pop.Solutions[pop.Best()].Fitness, -pop.MeanFitness())
+		}
+	}
+
+	if verbosity >= 1 {
+		best := pop.Solutions[pop.Best()]
+		fmt.Printf("Makespan %g: %v\n", -best.Fitness, best.Permutation())
+	}
+}
+
+// Function runBaseline performs the simple GA or ECGA on the host, with the
+// termination criteria and reports of GOMEA.
+func runBaseline(pop *ga.Population, rng *rand.Rand) {
+
+	if backend != "go" {
+		log.Fatalf("Fatal error: the baseline algorithms require the Go backend.")
+	}
+	if multiObjective || permutation || realValued {
+		log.Fatalf("Fatal error: the baseline algorithms only solve single-objective binary problems.")
+	}
+	if genotype.Alphabet > 2 {
+		log.Fatalf("Fatal error: the baseline algorithms require binary variables.")
+	}
+	if numIslands > 1 {
+		log.Fatalf("Fatal error: the baseline algorithms do not support islands.")
+	}
+
+	var b baseline
+
+	switch algorithm {
+	case "ga":
+		g := NewSimpleGA(hostEvaluator(), pop, rng)
+		switch crossoverName {
+		case "uniform":
+			g.Crossover = UniformCrossover
+		case "onepoint":
+			g.Crossover = OnePointCrossover
+		default:
+			log.Fatalf("Fatal error: unknown crossover %q.", crossoverName)
+		}
+		if tournamentSize > 0 {
+			g.TournamentSize = tournamentSize
+		}
+		if mutationRate >= 0 {
+			g.MutationRate = mutationRate
+		}
+		g.Tolerance = tolerance
+		b = g
+	case "ecga":
+		e := NewECGA(hostEvaluator(), pop, rng)
+		if tournamentSize > 0 {
+			e.TournamentSize = tournamentSize
+		}
+		e.Tolerance = tolerance
+		b = e
+	default:
+		log.Fatalf("Fatal error: unknown algorithm %q.", algorithm)
+	}
 
 	if verbosity >= 3 {
 		printGeneration(0, pop)
 	}
 
-	for !done {
+	evolveBaseline(b, pop, maxStalls)
+}
+
+// A baseline performs generations of the simple GA or ECGA. Step reports
+// whether the best solution found so far improved and whether an optimal
+// solution was found.
+type baseline interface {
+	Step() (improved, optimal bool)
+}
 
-		// Build the linkage tree and upload a flattened version to the compute device.
-		freqs := Frequencies(pop)
-		lt := LinkageTree(pop, freqs)
-		flattenIntoSlice(lt, ltData)
-
-		requireSuccess(cl.CLEnqueueWriteBuffer(
-			commandQueue, ltBuffer, cl.CL_TRUE, 0,
-			ltSize, unsafe.Pointer(&ltData[0]), 0, nil, nil),
-			"could not write data to an OpenCL memory buffer.")
-
-		// Store a flattened version of the population on the compute device.
-		populationToSlice(pop, populationData)
-		requireSuccess(cl.CLEnqueueWriteBuffer(
-			commandQueue, populationBuffer, cl.CL_TRUE, 0,
-			dataSize, unsafe.Pointer(&populationData[0]), 0, nil, nil),
-			"could not write data to an OpenCL memory buffer.")
-
-		// Set the GOM kernel arguments.
-		popSize := cl.CL_uint(pop.Size())
-		solLength := cl.CL_uint(pop.Length())
-		setKernelArg(kernel, 0, &populationBuffer)
-		setKernelArg(kernel, 1, &popSize)
-		setKernelArg(kernel, 2, &solLength)
-		setKernelArg(kernel, 3, &cloneBuffer)
-		setKernelArg(kernel, 4, &ltBuffer)
-		setKernelArg(kernel, 5, &improvsBuffer)
-		setKernelArg(kernel, 6, &offspringBuffer)
-
-		var globalWorkSize [1]cl.CL_size_t
-		globalWorkSize[0] = cl.CL_size_t(pop.Size())
-
-		// Perform GOM crossover.
-		requireSuccess(cl.CLEnqueueNDRangeKernel(
-			commandQueue, kernel, 1, nil, globalWorkSize[:],
-			nil, 0, nil, nil),
-			"could not enqueue OpenCL kernel.")
-
-		requireSuccess(cl.CLFinish(commandQueue), "could not finish command queue.")
-
-		// Retrieve the offspring population from the compute device.
-		requireSuccess(cl.CLEnqueueReadBuffer(
-			commandQueue, offspringBuffer, cl.CL_TRUE, 0,
-			dataSize, unsafe.Pointer(&offspringData[0]), 0, nil, nil),
-			"reading a buffer failed.")
-
-		requireSuccess(cl.CLEnqueueReadBuffer(
-			commandQueue, improvsBuffer, cl.CL_TRUE, 0,
-			improvsSize, unsafe.Pointer(&improvsData[0]), 0, nil, nil),
-			"reading improvs buffer failed.")
-
-		foundOptimal := sliceToPopulation(offspringData, pop)
+// Function evolveBaseline performs generations of the baseline on the
+// population until it terminates, after stallLimit generations without
+// improvement of the best solution at the latest, and returns the number
+// of generations.
+func evolveBaseline(b baseline, pop *ga.Population, stallLimit int) int {
+
+	done := false
+	window := stallWindow{limit: stallLimit}
+	generationsPassed := 0
 
+	for !done {
+		improved, foundOptimal := b.Step()
 		generationsPassed++
 
-		if (verbosity == 2 && done) || (verbosity == 3) {
-			printGeneration(generationsPassed, pop)
+		if e, ok := b.(*ECGA); ok && verbosity >= 3 {
+			log.Printf("Generation %d model: %v", generationsPassed, e.Model)
 		}
 
-		// TODO: Termination Criterion
-		if generationsPassed == numGenerations {
-			done = true
-		}
+		done = terminateGeneration(generationsPassed, pop, window.update(improved), foundOptimal)
+	}
 
-		improved := false
+	return generationsPassed
+}
 
-		for _, b := range improvsData {
-			if b > 0 {
-				improved = true
-				break
-			}
+// Function realEvaluator returns the evaluator of the real-valued problem
+// for the backend. The OpenCL backend evaluates on the first selected
+// device, which is released by the returned function.
+func realEvaluator(index int, p problem.RealProblem) (RealEvaluator, func()) {
+	if backend == "go" {
+		return hostRealEvaluator{p}, func() {}
+	}
+	if backend != "opencl" {
+		log.Fatalf("Fatal error: unknown backend %q.", backend)
+	}
+
+	var preferredType cl.CL_device_type
+
+	if useCPU {
+		preferredType = cl.CL_DEVICE_TYPE_CPU
+	} else {
+		preferredType = cl.CL_DEVICE_TYPE_GPU
+	}
+
+	device := selectDevices(getPlatforms(), preferredType)[0]
+
+	sources, err := realKernelSources(realProblems[index].clSource)
+	if err != nil {
+		log.Fatalf("Fatal error: %v", err)
+	}
+
+	// The rotation matrix is stored by rows.
+	var data []float64
+	if ellipsoid, ok := p.(*problem.RotatedEllipsoid); ok {
+		for _, row := range ellipsoid.Rotation {
+			data = append(data, row...)
 		}
+	}
 
-		if !improved {
-			if verbosity >= 2 {
-				log.Println("Terminated after the population did not improve for one generation.")
-			}
-			done = true
+	rd := newRealDevice(device, sources, populationSize, problemLength, data)
+	return rd, rd.release
+}
+
+// Function runReal performs real-valued GOMEA and reports the best
+// solution found.
+func runReal(rng *rand.Rand) {
+
+	if problemIndex < 0 || problemIndex >= len(realProblems) {
+		log.Fatalf("Fatal error: unknown real-valued problem %d, see -problem-list.", problemIndex)
+	}
+
+	p := realProblems[problemIndex].create(problemLength)
+	evaluator, release := realEvaluator(problemIndex, p)
+	defer release()
+
+	pop := ga.NewRealPopulation(populationSize, problemLength, realLower, realUpper, rng)
+	optimizer := NewRealOptimizer(evaluator, pop, rng)
+
+	done := false
+	window := stallWindow{limit: maxStalls}
+
+	for generationsPassed := 0; !done; {
+		stalled := window.update(optimizer.Step())
+		generationsPassed++
+
+		done = terminated(generationsPassed, stalled, false, optimizer.Best() <= valueToReach)
+
+		if verbosity >= 2 {
+			log.Printf("Generation %d: best %g, mean %g, std %g, evaluations %d",
+				generationsPassed, optimizer.Best(), -pop.MeanFitness(), pop.StdFitness(), optimizer.Evaluations)
 		}
+	}
 
-		if foundOptimal {
-			if verbosity >= 2 {
-				log.Printf("Optimal solution found after %d generations.\n", generationsPassed)
-			}
-			done = true
+	if verbosity >= 1 {
+		best := pop.Solutions[pop.Best()]
+		fmt.Printf("Objective %g: %v\n", -best.Fitness, best.Values)
+	}
+}
+
+// Function reportLinkage dumps the linkage model of an island in a
+// generation and reports its quality for problems with known building
+// blocks.
+func reportLinkage(generation, island int, pop *ga.Population, freqs [][][]int, lt [][]int, merges []Merge) {
+	if dumpInterval > 0 && generation%dumpInterval == 0 {
+		dumpLinkage(generation, island, lt, merges, distanceMatrix(pop, freqs))
+	}
+
+	if structured, ok := problems[problemIndex].evaluator.(problem.Structured); ok && verbosity >= 2 {
+		length := genotype.Variables(pop.Length())
+		lq := linkageQuality(lt, structured.Blocks(length), length)
+		if numIslands > 1 {
+			log.Printf("Generation %d island %d linkage: %v", generation, island, lq)
+		} else {
+			log.Printf("Generation %d linkage: %v", generation, lq)
 		}
 	}
 }
 
+// Function endGeneration reports the population after a generation and
+// tests the termination criteria.
+func endGeneration(generationsPassed int, pop *ga.Population, improved, foundOptimal bool) (done bool) {
+	window := stallWindow{limit: 1}
+	return terminateGeneration(generationsPassed, pop, window.update(improved), foundOptimal)
+}
+
+// Function terminateGeneration is endGeneration for a run that stalled for
+// the reason given by stalled, which is empty while it progresses.
+func terminateGeneration(generationsPassed int, pop *ga.Population, stalled string, foundOptimal bool) (done bool) {
+
+	if fallible, ok := problems[problemIndex].evaluator.(problem.Fallible); ok && fallible.Err() != nil {
+		log.Fatalf("Fatal error: evaluation failed: %v", fallible.Err())
+	}
+
+	done = terminated(generationsPassed, stalled, pop.Converged(), foundOptimal)
+
+	if (verbosity == 2 && done) || (verbosity == 3) {
+		printGeneration(generationsPassed, pop)
+	}
+
+	if verbosity >= 2 {
+		printStatistics(generationsPassed, pop)
+	}
+
+	return done
+}
+
 func main() {
 
 	parseCommandLine()
 
+	// The commands neither solve a problem nor start its evaluator.
+	switch flag.Arg(0) {
+	case "":
+	case "devices":
+		printDevices()
+		os.Exit(0)
+	case "clear-cache":
+		if err := clearKernelCache(); err != nil {
+			log.Fatalf("Fatal error: could not clear the kernel cache: %v", err)
+		}
+		os.Exit(0)
+	default:
+		log.Fatalf("Fatal error: unknown command %q.", flag.Arg(0))
+	}
+
 	if printProblems {
 		printProblemList()
 	}
 
-	runOpenCL()
+	parameterizedProblems()
+	customProblem()
+
+	if cacheSize > 0 {
+		fitnessCache = problem.NewCache(problems[problemIndex].evaluator, cacheSize)
+	}
+
+	seed := int64(randomSeed)
+	if randomSeed == 0 {
+		seed = time.Now().Unix()
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	pop, err := ga.InitPopulation(populationSize, problemLength*genotype.Width(), newInitializer(), rng)
+	if err != nil {
+		log.Fatalf("Fatal error: could not initialize the population: %v", err)
+	}
+
+	switch {
+	case algorithm != "gomea":
+		runBaseline(pop, rng)
+	case multiObjective:
+		runMO(pop, rng)
+	case permutation:
+		runPermutation(rng)
+	case realValued:
+		runReal(rng)
+	case backend == "opencl":
+		runOpenCL(pop, rng)
+	case backend == "go":
+		runGo(pop, rng)
+	default:
+		log.Fatalf("Fatal error: unknown backend %q.", backend)
+	}
+
+	if fitnessCache != nil && verbosity >= 2 {
+		hits, misses := fitnessCache.Stats()
+		log.Printf("Fitness cache: %d hits, %d misses, %d solutions cached.", hits, misses, fitnessCache.Len())
+	}
+
+	if closer, ok := problems[problemIndex].evaluator.(io.Closer); ok {
+		closer.Close()
+	}
 }
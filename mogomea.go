@@ -0,0 +1,340 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/Morenim/gom-opencl/bitset"
+	"github.com/Morenim/gom-opencl/ga"
+	"github.com/Morenim/gom-opencl/problem"
+)
+
+// Function dominates tests whether objectives a Pareto-dominate b: a is at
+// least b in every objective and greater in at least one.
+func dominates(a, b []float64) bool {
+	greater := false
+	for i := range a {
+		if a[i] < b[i] {
+			return false
+		}
+		if a[i] > b[i] {
+			greater = true
+		}
+	}
+	return greater
+}
+
+// Function weaklyDominates tests whether objectives a are at least b in
+// every objective.
+func weaklyDominates(a, b []float64) bool {
+	for i := range a {
+		if a[i] < b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Archive is an elitist archive holding the non-dominated solutions found
+// so far, with distinct objective values.
+type Archive struct {
+	Solutions  []bitset.BitSet
+	Objectives [][]float64
+}
+
+// Add adds a copy of the solution to the archive unless an archived
+// solution weakly dominates it, removing the archived solutions it
+// dominates. Add reports whether the solution was added.
+func (a *Archive) Add(bits bitset.BitSet, objectives []float64) bool {
+	for _, archived := range a.Objectives {
+		if weaklyDominates(archived, objectives) {
+			return false
+		}
+	}
+
+	n := 0
+	for i, archived := range a.Objectives {
+		if !dominates(objectives, archived) {
+			a.Solutions[n], a.Objectives[n] = a.Solutions[i], archived
+			n++
+		}
+	}
+	a.Solutions = append(a.Solutions[:n], bits.Clone())
+	a.Objectives = append(a.Objectives[:n], append([]float64(nil), objectives...))
+	return true
+}
+
+// Function hypervolume2D returns the area dominated by the points of a
+// bi-objective front and bounded by the reference point.
+func hypervolume2D(points [][]float64, reference []float64) float64 {
+	sorted := make([][]float64, 0, len(points))
+	for _, p := range points {
+		if p[0] > reference[0] && p[1] > reference[1] {
+			sorted = append(sorted, p)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i][0] > sorted[j][0] })
+
+	// Sweep from the highest first objective, adding the area each point
+	// adds above the previous points.
+	volume, height := 0.0, reference[1]
+	for _, p := range sorted {
+		if p[1] > height {
+			volume += (p[0] - reference[0]) * (p[1] - height)
+			height = p[1]
+		}
+	}
+	return volume
+}
+
+// Function writeFront writes the objective values and bit-strings of the
+// archive as CSV.
+func writeFront(w io.Writer, a *Archive) error {
+	order := make([]int, len(a.Objectives))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return a.Objectives[order[i]][0] < a.Objectives[order[j]][0] })
+
+	for _, i := range order {
+		for _, objective := range a.Objectives[i] {
+			if _, err := fmt.Fprintf(w, "%g,", objective); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%v\n", a.Solutions[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MOOptimizer performs MO-GOMEA on the host. Every generation it clusters
+// the population in objective space, learns a linkage tree per cluster,
+// and mixes every solution with donors from its cluster. Changes are kept
+// if they do not worsen any objective, or if they enter the elitist
+// archive.
+type MOOptimizer struct {
+	Problem    problem.MultiObjectiveProblem
+	Population *ga.Population
+	Rand       *rand.Rand
+	Clusters   int
+
+	Objectives [][]float64
+	Archive    Archive
+
+	// The clusters of the last generation with their linkage trees, and
+	// the cluster every solution is mixed in.
+	members    [][]int
+	fos        [][][]int
+	assignment []int
+}
+
+// NewMOOptimizer returns a multi-objective optimizer for the population
+// with the given number of clusters, evaluating every solution in the
+// population.
+func NewMOOptimizer(p problem.MultiObjectiveProblem, pop *ga.Population, clusters int, r *rand.Rand) *MOOptimizer {
+	o := &MOOptimizer{Problem: p, Population: pop, Rand: r, Clusters: clusters}
+
+	o.Objectives = make([][]float64, pop.Size())
+	for i, solution := range pop.Solutions {
+		o.Objectives[i] = p.EvaluateObjectives(solution.Bits)
+		o.Archive.Add(solution.Bits, o.Objectives[i])
+	}
+	o.updateFitness()
+
+	return o
+}
+
+// Function updateFitness stores the first objective as the fitness of the
+// solutions, for the population statistics.
+func (o *MOOptimizer) updateFitness() {
+	for i := range o.Population.Solutions {
+		o.Population.Solutions[i].Fitness = o.Objectives[i][0]
+	}
+}
+
+// Step performs a single generation and reports whether any offspring
+// dominates its parent or entered the archive.
+func (o *MOOptimizer) Step() (improved bool) {
+	o.cluster()
+	o.learn()
+
+	offspring := make([]ga.Solution, o.Population.Size())
+	objectives := make([][]float64, o.Population.Size())
+
+	for i := range o.Population.Solutions {
+		var better bool
+		offspring[i], objectives[i], better = o.mix(i)
+		improved = improved || better
+	}
+
+	o.Population.Solutions, o.Objectives = offspring, objectives
+	o.updateFitness()
+
+	return improved
+}
+
+// Function normalized returns the objectives of the population scaled to
+// [0, 1] per objective.
+func (o *MOOptimizer) normalized() [][]float64 {
+	m := o.Problem.Objectives()
+	low, high := make([]float64, m), make([]float64, m)
+	for j := 0; j < m; j++ {
+		low[j], high[j] = math.Inf(1), math.Inf(-1)
+	}
+	for _, objectives := range o.Objectives {
+		for j, v := range objectives {
+			low[j], high[j] = math.Min(low[j], v), math.Max(high[j], v)
+		}
+	}
+
+	points := make([][]float64, len(o.Objectives))
+	for i, objectives := range o.Objectives {
+		points[i] = make([]float64, m)
+		for j, v := range objectives {
+			if high[j] > low[j] {
+				points[i][j] = (v - low[j]) / (high[j] - low[j])
+			}
+		}
+	}
+	return points
+}
+
+// Function euclidean returns the Euclidean distance between two points.
+func euclidean(a, b []float64) float64 {
+	d := 0.0
+	for i := range a {
+		d += (a[i] - b[i]) * (a[i] - b[i])
+	}
+	return math.Sqrt(d)
+}
+
+// Function cluster chooses well-spread leaders in normalized objective
+// space, starting from a random solution and repeatedly adding the
+// solution farthest from all leaders. Every cluster holds the 2N/k
+// solutions nearest to its leader, so clusters may overlap, and every
+// solution is mixed in the cluster of its nearest leader.
+func (o *MOOptimizer) cluster() {
+	points := o.normalized()
+	n := len(points)
+
+	k := o.Clusters
+	if k > n {
+		k = n
+	}
+	if k < 1 {
+		k = 1
+	}
+
+	leaders := []int{o.Rand.Intn(n)}
+	nearest := make([]float64, n)
+	for i := range nearest {
+		nearest[i] = euclidean(points[i], points[leaders[0]])
+	}
+	for len(leaders) < k {
+		farthest := 0
+		for i := range points {
+			if nearest[i] > nearest[farthest] {
+				farthest = i
+			}
+		}
+		leaders = append(leaders, farthest)
+		for i := range nearest {
+			nearest[i] = math.Min(nearest[i], euclidean(points[i], points[farthest]))
+		}
+	}
+
+	size := 2 * n / k
+	if size > n {
+		size = n
+	}
+	if size < 2 {
+		size = 2
+	}
+
+	o.members = make([][]int, k)
+	for c, leader := range leaders {
+		order := o.Rand.Perm(n)
+		sort.SliceStable(order, func(a, b int) bool {
+			return euclidean(points[order[a]], points[leader]) < euclidean(points[order[b]], points[leader])
+		})
+		o.members[c] = order[:size]
+	}
+
+	o.assignment = make([]int, n)
+	for i := range points {
+		for c, leader := range leaders {
+			if euclidean(points[i], points[leader]) < euclidean(points[i], points[leaders[o.assignment[i]]]) {
+				o.assignment[i] = c
+			}
+		}
+	}
+}
+
+// Function learn learns the linkage tree of every cluster.
+func (o *MOOptimizer) learn() {
+	o.fos = make([][][]int, len(o.members))
+	for c, members := range o.members {
+		cluster := &ga.Population{Solutions: make([]ga.Solution, len(members))}
+		for j, i := range members {
+			cluster.Solutions[j] = o.Population.Solutions[i]
+		}
+		o.fos[c], _ = LinkageTreeMerges(cluster, Frequencies(cluster), o.Rand)
+	}
+}
+
+// Function mix applies GOM to solution i with donors from its cluster and
+// returns the offspring with its objectives, and whether it dominates its
+// parent or entered the archive.
+func (o *MOOptimizer) mix(i int) (offspring ga.Solution, objectives []float64, improved bool) {
+	c := o.assignment[i]
+	members := o.members[c]
+
+	offspring.Bits = o.Population.Solutions[i].Bits.Clone()
+	objectives = o.Objectives[i]
+	clone := offspring.Bits.Clone()
+
+	for _, subset := range o.fos[c] {
+		donor := o.Population.Solutions[members[o.Rand.Intn(len(members))]]
+		if sameBits(offspring.Bits, donor.Bits, subset) {
+			continue
+		}
+
+		offspring.Bits.CopyBits(donor.Bits, subset)
+		candidate := o.Problem.EvaluateObjectives(offspring.Bits)
+
+		archived := o.Archive.Add(offspring.Bits, candidate)
+		if archived || weaklyDominates(candidate, objectives) {
+			if archived || dominates(candidate, objectives) {
+				improved = true
+			}
+			clone.CopyBits(offspring.Bits, subset)
+			objectives = candidate
+		} else {
+			offspring.Bits.CopyBits(clone, subset)
+		}
+	}
+
+	return offspring, objectives, improved
+}
+
+// Hypervolume returns the hypervolume of the archive of a bi-objective
+// problem with respect to the reference point, which defaults to the
+// lowest values in the archive minus one.
+func (o *MOOptimizer) Hypervolume(length int) float64 {
+	var reference []float64
+	if referenced, ok := o.Problem.(problem.Referenced); ok {
+		reference = referenced.Reference(length)
+	} else {
+		reference = []float64{math.Inf(1), math.Inf(1)}
+		for _, objectives := range o.Archive.Objectives {
+			reference[0] = math.Min(reference[0], objectives[0]-1)
+			reference[1] = math.Min(reference[1], objectives[1]-1)
+		}
+	}
+	return hypervolume2D(o.Archive.Objectives, reference)
+}
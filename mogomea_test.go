@@ -0,0 +1,101 @@
+package main
+
+import (
+	"github.com/Morenim/gom-opencl/bitset"
+	"github.com/Morenim/gom-opencl/ga"
+	"github.com/Morenim/gom-opencl/problem"
+	"math/rand"
+	"testing"
+)
+
+func TestDominates(t *testing.T) {
+	tests := []struct {
+		a, b   []float64
+		strict bool
+		weak   bool
+	}{
+		{[]float64{2, 2}, []float64{1, 1}, true, true},
+		{[]float64{2, 1}, []float64{1, 1}, true, true},
+		{[]float64{1, 1}, []float64{1, 1}, false, true},
+		{[]float64{2, 0}, []float64{1, 1}, false, false},
+		{[]float64{0, 0}, []float64{1, 1}, false, false},
+	}
+
+	for _, test := range tests {
+		if actual := dominates(test.a, test.b); actual != test.strict {
+			t.Errorf("dominates(%v, %v) = %v, expected %v.", test.a, test.b, actual, test.strict)
+		}
+		if actual := weaklyDominates(test.a, test.b); actual != test.weak {
+			t.Errorf("weaklyDominates(%v, %v) = %v, expected %v.", test.a, test.b, actual, test.weak)
+		}
+	}
+}
+
+func TestArchiveAdd(t *testing.T) {
+	var a Archive
+	bits := bitset.New(4)
+
+	steps := []struct {
+		objectives []float64
+		added      bool
+		size       int
+	}{
+		{[]float64{1, 3}, true, 1},
+		{[]float64{3, 1}, true, 2},
+		{[]float64{1, 3}, false, 2},
+		{[]float64{0, 2}, false, 2},
+		{[]float64{2, 2}, true, 3},
+		{[]float64{3, 3}, true, 1},
+	}
+
+	for i, step := range steps {
+		if added := a.Add(bits, step.objectives); added != step.added {
+			t.Errorf("Step %d: Add(%v) = %v, expected %v.", i, step.objectives, added, step.added)
+		}
+		if len(a.Objectives) != step.size || len(a.Solutions) != step.size {
+			t.Errorf("Step %d: archive holds %d solutions, expected %d.", i, len(a.Objectives), step.size)
+		}
+	}
+}
+
+func TestHypervolume2D(t *testing.T) {
+	tests := []struct {
+		points    [][]float64
+		reference []float64
+		expected  float64
+	}{
+		{nil, []float64{0, 0}, 0},
+		{[][]float64{{2, 3}}, []float64{0, 0}, 6},
+		{[][]float64{{1, 3}, {3, 1}}, []float64{0, 0}, 5},
+		{[][]float64{{1, 3}, {2, 2}, {3, 1}}, []float64{0, 0}, 6},
+		{[][]float64{{1, 3}, {1, 2}}, []float64{0, 0}, 3},
+		{[][]float64{{1, 3}, {-1, 5}}, []float64{0, 0}, 3},
+	}
+
+	for _, test := range tests {
+		if actual := hypervolume2D(test.points, test.reference); actual != test.expected {
+			t.Errorf("hypervolume2D(%v, %v) = %v, expected %v.", test.points, test.reference, actual, test.expected)
+		}
+	}
+}
+
+func TestMOOptimizerFindsExtremes(t *testing.T) {
+	const length = 16
+
+	r := rand.New(rand.NewSource(1))
+	pop := ga.NewPopulation(100, length, r)
+	optimizer := NewMOOptimizer(problem.TrapInverseTrap(4), pop, 5, r)
+
+	for i := 0; i < 50 && optimizer.Step(); i++ {
+	}
+
+	ones, zeros := false, false
+	for _, objectives := range optimizer.Archive.Objectives {
+		ones = ones || objectives[0] == length
+		zeros = zeros || objectives[1] == length
+	}
+
+	if !ones || !zeros {
+		t.Errorf("The archive %v lacks an extreme of the front.", optimizer.Archive.Objectives)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/Morenim/gom-opencl/ga"
+	"github.com/Morenim/gom-opencl/problem"
+)
+
+// PermutationOptimizer performs Permutation GOMEA on the host, with
+// permutations encoded by random keys. Every generation the keys are
+// re-encoded, a linkage tree is learned from the relative ordering of the
+// keys, and GOM copies the keys of every FOS subset from a random donor.
+type PermutationOptimizer struct {
+	Problem    problem.PermutationProblem
+	Population *ga.KeyPopulation
+	Rand       *rand.Rand
+
+	// RescaleProbability is the probability that the keys copied from a
+	// donor are rescaled into a random interval, moving the copied jobs
+	// as a group to another part of the permutation.
+	RescaleProbability float64
+
+	// The linkage model learned in the last generation.
+	FOS    [][]int
+	Merges []Merge
+
+	// Whether the solutions of the population are optimal.
+	optimal []bool
+}
+
+// NewPermutationOptimizer returns an optimizer for the population,
+// evaluating every solution in the population.
+func NewPermutationOptimizer(p problem.PermutationProblem, pop *ga.KeyPopulation, r *rand.Rand) *PermutationOptimizer {
+	o := &PermutationOptimizer{Problem: p, Population: pop, Rand: r, RescaleProbability: 0.1}
+
+	o.optimal = make([]bool, pop.Size())
+	for i, solution := range pop.Solutions {
+		pop.Solutions[i].Fitness, o.optimal[i] = p.EvaluatePermutation(solution.Permutation())
+	}
+
+	return o
+}
+
+// Optimal tests whether the population contains an optimal solution.
+func (o *PermutationOptimizer) Optimal() bool {
+	for _, optimal := range o.optimal {
+		if optimal {
+			return true
+		}
+	}
+	return false
+}
+
+// Step performs a single generation: it re-encodes the keys, learns the
+// linkage tree and replaces every solution by the result of mixing it with
+// random donors. Step reports whether any solution improved and whether an
+// optimal solution was found.
+func (o *PermutationOptimizer) Step() (improved, optimal bool) {
+	for _, solution := range o.Population.Solutions {
+		solution.Reencode(o.Rand)
+	}
+
+	o.Learn()
+
+	offspring := make([]ga.KeySolution, o.Population.Size())
+	for i := range offspring {
+		var better bool
+		offspring[i], o.optimal[i], better = o.mix(i)
+		improved = improved || better
+	}
+	o.Population.Solutions = offspring
+
+	return improved, o.Optimal()
+}
+
+// Learn learns the linkage tree of the population.
+func (o *PermutationOptimizer) Learn() {
+	o.FOS, o.Merges = clusterMatrix(orderingMatrix(o.Population), o.Rand)
+}
+
+// Function orderingMatrix computes the dependency between every pair of
+// random keys as in Permutation GOMEA: the product of how consistently one
+// key precedes the other, one minus the binary entropy of the fraction of
+// solutions in which key i is smaller, and how close the keys are, one
+// minus their mean squared difference.
+func orderingMatrix(pop *ga.KeyPopulation) *matrix {
+	n := pop.Length()
+	dependencies := newMatrix(n)
+
+	for i := 0; i < n; i++ {
+		for j := 0; j < i; j++ {
+			before, squares := 0, 0.0
+			for _, solution := range pop.Solutions {
+				if solution.Keys[i] < solution.Keys[j] {
+					before++
+				}
+				d := solution.Keys[i] - solution.Keys[j]
+				squares += d * d
+			}
+
+			freqs := []int{before, pop.Size() - before}
+			ordering := 1 - entropy(freqs, pop.Size())/math.Ln2
+			proximity := 1 - squares/float64(pop.Size())
+			dependencies.set(i, j, ordering*proximity)
+		}
+	}
+
+	return dependencies
+}
+
+// Function mix applies GOM to solution i, copying the keys of every FOS
+// subset from a random donor and keeping the change unless it lowers the
+// fitness. Mix returns the offspring, whether it is optimal and whether it
+// improved on its parent.
+func (o *PermutationOptimizer) mix(i int) (offspring ga.KeySolution, optimal, improved bool) {
+	parent := o.Population.Solutions[i]
+
+	offspring.Fitness = parent.Fitness
+	offspring.Keys = append([]float64(nil), parent.Keys...)
+	optimal = o.optimal[i]
+
+	// The backup holds the keys of the offspring before the current change.
+	backup := append([]float64(nil), parent.Keys...)
+
+	for _, subset := range o.FOS {
+		donor := o.Population.Solutions[o.Rand.Intn(o.Population.Size())]
+
+		for _, j := range subset {
+			offspring.Keys[j] = donor.Keys[j]
+		}
+		if o.Rand.Float64() < o.RescaleProbability {
+			ga.Rescale(offspring.Keys, subset, o.Rand)
+		}
+
+		fitness, isOptimal := o.Problem.EvaluatePermutation(offspring.Permutation())
+
+		if fitness >= offspring.Fitness {
+			for _, j := range subset {
+				backup[j] = offspring.Keys[j]
+			}
+			if fitness > offspring.Fitness {
+				improved = true
+			}
+			offspring.Fitness, optimal = fitness, isOptimal
+		} else {
+			for _, j := range subset {
+				offspring.Keys[j] = backup[j]
+			}
+		}
+	}
+
+	return offspring, optimal, improved
+}
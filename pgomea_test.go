@@ -0,0 +1,74 @@
+package main
+
+import (
+	"github.com/Morenim/gom-opencl/ga"
+	"github.com/Morenim/gom-opencl/problem"
+	"math/rand"
+	"testing"
+)
+
+func TestOrderingMatrix(t *testing.T) {
+	// Keys 0 and 1 are always adjacent in the same order, while key 2
+	// precedes and follows them equally often.
+	pop := &ga.KeyPopulation{Solutions: []ga.KeySolution{
+		{Keys: []float64{0.1, 0.2, 0.9}},
+		{Keys: []float64{0.5, 0.6, 0.0}},
+		{Keys: []float64{0.3, 0.4, 0.8}},
+		{Keys: []float64{0.7, 0.8, 0.2}},
+	}}
+
+	m := orderingMatrix(pop)
+	if m.get(0, 1) <= m.get(0, 2) || m.get(0, 1) <= m.get(1, 2) {
+		t.Errorf("orderingMatrix did not rank the adjacent keys highest:\n%v", m)
+	}
+	if m.get(0, 2) != 0 {
+		t.Errorf("orderingMatrix(0, 2) = %v for keys in random order, expected 0.", m.get(0, 2))
+	}
+}
+
+func TestPermutationOptimizerImproves(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	f := problem.RandomFlowShop(20, 5, r)
+	pop := ga.NewKeyPopulation(100, 20, r)
+	optimizer := NewPermutationOptimizer(f, pop, r)
+
+	initial := pop.Solutions[pop.Best()].Fitness
+	for generation := 0; generation < 20; generation++ {
+		optimizer.Step()
+	}
+
+	best := pop.Solutions[pop.Best()]
+	if best.Fitness <= initial {
+		t.Errorf("Optimizer did not improve the makespan %v.", -initial)
+	}
+	if makespan := f.Makespan(best.Permutation()); makespan != -best.Fitness {
+		t.Errorf("Best solution has makespan %v, but fitness %v.", makespan, best.Fitness)
+	}
+}
+
+func TestPermutationOptimizerSolvesSorting(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	pop := ga.NewKeyPopulation(200, 12, r)
+	optimizer := NewPermutationOptimizer(sorting{}, pop, r)
+
+	for generation := 0; generation < 50 && !optimizer.Optimal(); generation++ {
+		optimizer.Step()
+	}
+
+	if !optimizer.Optimal() {
+		t.Errorf("Optimizer did not sort, best %v.", pop.Solutions[pop.Best()])
+	}
+}
+
+// A sorting problem counts the positions holding their own index.
+type sorting struct{}
+
+func (sorting) EvaluatePermutation(perm []int) (float64, bool) {
+	fitness := 0.0
+	for i, v := range perm {
+		if i == v {
+			fitness++
+		}
+	}
+	return fitness, fitness == float64(len(perm))
+}
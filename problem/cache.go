@@ -0,0 +1,235 @@
+package problem
+
+import (
+	"container/list"
+	"encoding/binary"
+	"hash/maphash"
+	"sync"
+
+	"github.com/Morenim/gom-opencl/bitset"
+)
+
+// Cache memoises the fitness values of a problem, so solutions evaluated
+// before are not evaluated again. It holds at most a fixed number of
+// solutions, evicting the least recently used solution when full. A Cache
+// is safe for concurrent use if the problem is.
+type Cache struct {
+	problem  Problem
+	capacity int
+	seed     maphash.Seed
+
+	mu      sync.Mutex
+	entries map[uint64][]*list.Element
+	order   *list.List
+	hits    int
+	misses  int
+}
+
+// A cacheEntry is a solution with its fitness, stored as the words of
+// PutUInt32s to tell apart solutions with the same hash.
+type cacheEntry struct {
+	hash    uint64
+	length  int
+	words   []uint32
+	fitness float64
+	optimal bool
+}
+
+// NewCache returns a cache of the problem holding at most capacity
+// solutions.
+func NewCache(p Problem, capacity int) *Cache {
+	return &Cache{
+		problem:  p,
+		capacity: capacity,
+		seed:     maphash.MakeSeed(),
+		entries:  make(map[uint64][]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Function key returns the words of the solution and their hash.
+func (c *Cache) key(bits bitset.BitSet) (uint64, []uint32) {
+	words := make([]uint32, bitset.UInt32Len(bits.Len()))
+	bits.PutUInt32s(words)
+
+	var h maphash.Hash
+	h.SetSeed(c.seed)
+
+	var buf [4]byte
+	for _, w := range words {
+		binary.LittleEndian.PutUint32(buf[:], w)
+		h.Write(buf[:])
+	}
+	return h.Sum64(), words
+}
+
+// Function lookup returns the entry of the solution and marks it as most
+// recently used, or nil if it is not cached. The caller holds the lock.
+func (c *Cache) lookup(hash uint64, length int, words []uint32) *cacheEntry {
+	for _, element := range c.entries[hash] {
+		entry := element.Value.(*cacheEntry)
+		if entry.length == length && equalWords(entry.words, words) {
+			c.order.MoveToFront(element)
+			return entry
+		}
+	}
+	return nil
+}
+
+func equalWords(a, b []uint32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Function store caches the entry, evicting the least recently used
+// entries beyond the capacity. The caller holds the lock.
+func (c *Cache) store(entry *cacheEntry) {
+	if c.capacity <= 0 || c.lookup(entry.hash, entry.length, entry.words) != nil {
+		return
+	}
+
+	c.entries[entry.hash] = append(c.entries[entry.hash], c.order.PushFront(entry))
+
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+
+		hash := oldest.Value.(*cacheEntry).hash
+		elements := c.entries[hash]
+		for i, element := range elements {
+			if element == oldest {
+				elements = append(elements[:i], elements[i+1:]...)
+				break
+			}
+		}
+		if len(elements) == 0 {
+			delete(c.entries, hash)
+		} else {
+			c.entries[hash] = elements
+		}
+	}
+}
+
+func (c *Cache) Evaluate(bits bitset.BitSet) (float64, bool) {
+	hash, words := c.key(bits)
+
+	c.mu.Lock()
+	if entry := c.lookup(hash, bits.Len(), words); entry != nil {
+		c.hits++
+		c.mu.Unlock()
+		return entry.fitness, entry.optimal
+	}
+	c.misses++
+	c.mu.Unlock()
+
+	// The problem is evaluated without holding the lock, so concurrent
+	// evaluations of different solutions proceed in parallel.
+	fitness, optimal := c.problem.Evaluate(bits)
+
+	// Failed evaluations are not cached.
+	if fallible, ok := c.problem.(Fallible); ok && fallible.Err() != nil {
+		return fitness, optimal
+	}
+
+	c.mu.Lock()
+	c.store(&cacheEntry{hash, bits.Len(), words, fitness, optimal})
+	c.mu.Unlock()
+
+	return fitness, optimal
+}
+
+// EvaluateBatch evaluates the solutions that are not cached at once if the
+// problem is Batched, and one by one otherwise.
+func (c *Cache) EvaluateBatch(solutions []bitset.BitSet) (fitness []float64, optimal []bool) {
+	fitness = make([]float64, len(solutions))
+	optimal = make([]bool, len(solutions))
+
+	batched, ok := c.problem.(Batched)
+	if !ok {
+		for i, bits := range solutions {
+			fitness[i], optimal[i] = c.Evaluate(bits)
+		}
+		return fitness, optimal
+	}
+
+	var missing []int
+	var entries []*cacheEntry
+
+	c.mu.Lock()
+	for i, bits := range solutions {
+		hash, words := c.key(bits)
+		if entry := c.lookup(hash, bits.Len(), words); entry != nil {
+			c.hits++
+			fitness[i], optimal[i] = entry.fitness, entry.optimal
+			continue
+		}
+		c.misses++
+		missing = append(missing, i)
+		entries = append(entries, &cacheEntry{hash: hash, length: bits.Len(), words: words})
+	}
+	c.mu.Unlock()
+
+	if len(missing) == 0 {
+		return fitness, optimal
+	}
+
+	batch := make([]bitset.BitSet, len(missing))
+	for j, i := range missing {
+		batch[j] = solutions[i]
+	}
+	batchFitness, batchOptimal := batched.EvaluateBatch(batch)
+
+	failed := false
+	if fallible, ok := c.problem.(Fallible); ok && fallible.Err() != nil {
+		failed = true
+	}
+
+	c.mu.Lock()
+	for j, i := range missing {
+		fitness[i], optimal[i] = batchFitness[j], batchOptimal[j]
+		if !failed {
+			entries[j].fitness, entries[j].optimal = batchFitness[j], batchOptimal[j]
+			c.store(entries[j])
+		}
+	}
+	c.mu.Unlock()
+
+	return fitness, optimal
+}
+
+// Violation returns the constraint violation of the solution if the problem
+// is constrained. Violations are cheap to compute and are not memoised.
+func (c *Cache) Violation(bits bitset.BitSet) float64 {
+	return Violation(c.problem, bits)
+}
+
+// Stats returns the number of evaluations answered from the cache and the
+// number of evaluations of the problem.
+func (c *Cache) Stats() (hits, misses int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+// Len returns the number of cached solutions.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Err returns the first failed evaluation of the problem, if it is
+// Fallible.
+func (c *Cache) Err() error {
+	if fallible, ok := c.problem.(Fallible); ok {
+		return fallible.Err()
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package problem
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/Morenim/gom-opencl/bitset"
+)
+
+// A countingProblem counts the evaluations of the deceptive trap.
+type countingProblem struct {
+	mu          sync.Mutex
+	evaluations int
+}
+
+func (p *countingProblem) Evaluate(bits bitset.BitSet) (float64, bool) {
+	p.mu.Lock()
+	p.evaluations++
+	p.mu.Unlock()
+	return DeceptiveTrap(4).Evaluate(bits)
+}
+
+func mustParse(t *testing.T, s string) bitset.BitSet {
+	bits, err := bitset.FromString(s)
+	if err != nil {
+		t.Fatalf("FromString(%q) returned error %q.", s, err)
+	}
+	return bits
+}
+
+func TestCacheHits(t *testing.T) {
+	p := new(countingProblem)
+	c := NewCache(p, 10)
+
+	for _, s := range []string{"11110000", "11110000", "00001111", "11110000"} {
+		bits := mustParse(t, s)
+		expected, _ := DeceptiveTrap(4).Evaluate(bits)
+		if fitness, _ := c.Evaluate(bits); fitness != expected {
+			t.Errorf("Evaluate(%s) = %v, expected %v.", s, fitness, expected)
+		}
+	}
+
+	if hits, misses := c.Stats(); hits != 2 || misses != 2 || p.evaluations != 2 {
+		t.Errorf("Stats() = %d, %d with %d evaluations, expected 2, 2 and 2.", hits, misses, p.evaluations)
+	}
+
+	// Solutions of different lengths with equal words are told apart.
+	c.Evaluate(mustParse(t, "0011110000"))
+	if _, misses := c.Stats(); misses != 3 {
+		t.Errorf("A longer solution with equal words hit the cache.")
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	p := new(countingProblem)
+	c := NewCache(p, 2)
+
+	a, b, d := mustParse(t, "1000"), mustParse(t, "0100"), mustParse(t, "0010")
+
+	c.Evaluate(a)
+	c.Evaluate(b)
+	c.Evaluate(a) // a is now more recently used than b.
+	c.Evaluate(d) // Evicts b.
+
+	if c.Len() != 2 {
+		t.Errorf("Len() = %d, expected 2.", c.Len())
+	}
+
+	p.evaluations = 0
+	c.Evaluate(a)
+	c.Evaluate(d)
+	if p.evaluations != 0 {
+		t.Errorf("Recently used solutions were evicted.")
+	}
+	c.Evaluate(b)
+	if p.evaluations != 1 {
+		t.Errorf("The least recently used solution was not evicted.")
+	}
+}
@@ -0,0 +1,44 @@
+package problem
+
+import (
+	"github.com/Morenim/gom-opencl/bitset"
+)
+
+// ConstrainedProblem is implemented by problems with constraints. Violation
+// returns how much a solution violates the constraints, which is zero if
+// and only if the solution is feasible.
+type ConstrainedProblem interface {
+	Problem
+	Violation(bits bitset.BitSet) float64
+}
+
+// Violation returns the constraint violation of a solution to the problem,
+// which is zero for problems without constraints.
+func Violation(p Problem, bits bitset.BitSet) float64 {
+	if constrained, ok := p.(ConstrainedProblem); ok {
+		return constrained.Violation(bits)
+	}
+	return 0
+}
+
+// ConstraintGeq tests whether a solution with fitness a and violation va is
+// at least as good as a solution with fitness b and violation vb under
+// constraint domination: feasible solutions are compared by fitness up to
+// the tolerance, a feasible solution beats an infeasible one, and among
+// infeasible solutions the lower violation is better.
+func ConstraintGeq(a, va, b, vb, tolerance float64) bool {
+	if va == 0 && vb == 0 {
+		return a+tolerance >= b
+	}
+	return va <= vb
+}
+
+// ConstraintGt tests whether a solution with fitness a and violation va is
+// better than a solution with fitness b and violation vb under constraint
+// domination, see ConstraintGeq.
+func ConstraintGt(a, va, b, vb, tolerance float64) bool {
+	if va == 0 && vb == 0 {
+		return a > b+tolerance
+	}
+	return va < vb
+}
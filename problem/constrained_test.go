@@ -0,0 +1,61 @@
+package problem
+
+import (
+	"testing"
+)
+
+var constraintTests = []struct {
+	a, va, b, vb float64
+	geq, gt      bool
+}{
+	{2, 0, 1, 0, true, true},
+	{1, 0, 1, 0, true, false},
+	{1, 0, 2, 0, false, false},
+	{1, 0, 9, 3, true, true},
+	{9, 3, 1, 0, false, false},
+	{1, 2, 9, 3, true, true},
+	{9, 3, 1, 3, true, false},
+	{9, 3, 1, 2, false, false},
+}
+
+func TestConstraintDomination(t *testing.T) {
+	for _, test := range constraintTests {
+		if actual := ConstraintGeq(test.a, test.va, test.b, test.vb, 0); actual != test.geq {
+			t.Errorf("ConstraintGeq(%v, %v, %v, %v) = %v, expected %v.", test.a, test.va, test.b, test.vb, actual, test.geq)
+		}
+		if actual := ConstraintGt(test.a, test.va, test.b, test.vb, 0); actual != test.gt {
+			t.Errorf("ConstraintGt(%v, %v, %v, %v) = %v, expected %v.", test.a, test.va, test.b, test.vb, actual, test.gt)
+		}
+	}
+}
+
+func TestKnapsack(t *testing.T) {
+	k := &Knapsack{Profits: []float64{10, 20, 30}, Weights: []float64{5, 10, 15}, Capacity: 20}
+
+	// The first item is the rightmost bit.
+	tests := []struct {
+		bits      string
+		profit    float64
+		violation float64
+	}{
+		{"000", 0, 0},
+		{"011", 30, 0},
+		{"101", 40, 0},
+		{"110", 50, 5},
+		{"111", 60, 10},
+	}
+
+	for _, test := range tests {
+		bits := mustParse(t, test.bits)
+		if profit, _ := k.Evaluate(bits); profit != test.profit {
+			t.Errorf("Evaluate(%s) = %v, expected %v.", test.bits, profit, test.profit)
+		}
+		if violation := Violation(k, bits); violation != test.violation {
+			t.Errorf("Violation(%s) = %v, expected %v.", test.bits, violation, test.violation)
+		}
+	}
+
+	if violation := Violation(DeceptiveTrap(4), mustParse(t, "1111")); violation != 0 {
+		t.Errorf("Violation of an unconstrained problem = %v, expected 0.", violation)
+	}
+}
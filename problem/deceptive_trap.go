@@ -26,3 +26,23 @@ func (dt DeceptiveTrap) Evaluate(bits bitset.BitSet) (fitness float64, optimal b
 	}
 	return
 }
+
+// Blocks returns the consecutive, non-overlapping traps of size k.
+func (dt DeceptiveTrap) Blocks(length int) [][]int {
+	k := int(dt)
+	blocks := make([][]int, 0, length/k)
+
+	for i := 0; i+k <= length; i += k {
+		block := make([]int, k)
+		for j := range block {
+			block[j] = i + j
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// Optimum returns the fitness of the all-ones bit-string.
+func (dt DeceptiveTrap) Optimum(length int) float64 {
+	return float64(length / int(dt) * int(dt))
+}
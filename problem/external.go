@@ -0,0 +1,494 @@
+package problem
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Morenim/gom-opencl/bitset"
+)
+
+// Fallible is implemented by problems whose evaluations can fail, such as
+// external evaluators. Evaluate reports a failed evaluation with the
+// lowest possible fitness, and Err returns the first failure.
+type Fallible interface {
+	Err() error
+}
+
+// Batched is implemented by problems that evaluate several solutions more
+// efficiently at once than one by one.
+type Batched interface {
+	EvaluateBatch(solutions []bitset.BitSet) (fitness []float64, optimal []bool)
+}
+
+// External evaluates solutions with an external program, connected over
+// its standard input and output or over a TCP or Unix socket. Solutions
+// are sent in batches, and every connection handles one batch at a time.
+//
+// In the line protocol a batch of n solutions is sent as n lines, each
+// holding an encoded solution. The evaluator replies with n lines in the
+// same order, each holding the fitness of the solution, optionally
+// followed by a space and 1 if the solution is optimal:
+//
+//	> 0110
+//	> 1111
+//	< 2
+//	< 4 1
+//
+// In the JSON protocol a batch is sent as a single line holding an object
+// with the encoded solutions. The evaluator replies with a single line
+// holding an object with the fitness values, optionally whether the
+// solutions are optimal, and optionally an error message failing the
+// evaluation:
+//
+//	> {"solutions":["0110","1111"]}
+//	< {"fitness":[2,4],"optimal":[false,true]}
+//
+// Solutions are encoded as strings of 0s and 1s with the first variable
+// last, as printed by bitset.BitSet, or in the binary encoding of
+// bitset.BitSet (a version byte 1, the uvarint length, then the bits in
+// least-significant-first bytes) in hexadecimal or standard base64.
+type External struct {
+	options  ExternalOptions
+	encode   func(bitset.BitSet) string
+	conns    []io.ReadWriteCloser
+	wait     []func() error
+	requests chan []*request
+	batches  chan []*request
+	workers  sync.WaitGroup
+
+	mu     sync.Mutex
+	err    error
+	closed bool
+}
+
+// ExternalOptions configures the protocol of an external evaluator.
+type ExternalOptions struct {
+	// Encoding of the solutions: text, hex or base64.
+	Encoding string
+
+	// Whether to use the JSON protocol instead of the line protocol.
+	JSON bool
+
+	// The maximum number of solutions per batch, and how long a batch
+	// waits for further solutions to arrive before it is sent.
+	BatchSize  int
+	BatchDelay time.Duration
+
+	// How long the evaluator may take to reply to a batch. Disabled if 0.
+	Timeout time.Duration
+
+	// The number of processes or socket connections evaluating batches
+	// concurrently.
+	Connections int
+}
+
+// DefaultExternalOptions evaluates solutions one at a time over a single
+// connection with the line protocol in the text encoding.
+var DefaultExternalOptions = ExternalOptions{Encoding: "text", BatchSize: 1, Connections: 1}
+
+// A request is a solution waiting in a batch for its evaluation.
+type request struct {
+	bits    bitset.BitSet
+	fitness float64
+	optimal bool
+	done    chan struct{}
+}
+
+// Command starts Connections instances of the program with the arguments
+// as an external evaluator. Their standard error is passed through, so the
+// diagnostics of a failing program are shown.
+func Command(options ExternalOptions, name string, args ...string) (*External, error) {
+	e, err := newExternal(options)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < e.options.Connections; i++ {
+		cmd := exec.Command(name, args...)
+		cmd.Stderr = os.Stderr
+
+		in, err := cmd.StdinPipe()
+		if err != nil {
+			e.Close()
+			return nil, err
+		}
+		out, err := cmd.StdoutPipe()
+		if err != nil {
+			e.Close()
+			return nil, err
+		}
+		if err := cmd.Start(); err != nil {
+			e.Close()
+			return nil, err
+		}
+
+		e.conns = append(e.conns, pipe{out, in})
+		e.wait = append(e.wait, cmd.Wait)
+	}
+
+	e.start()
+	return e, nil
+}
+
+// Dial connects Connections times to an external evaluator listening on
+// the network address, where the network is tcp or unix.
+func Dial(options ExternalOptions, network, address string) (*External, error) {
+	e, err := newExternal(options)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < e.options.Connections; i++ {
+		conn, err := net.Dial(network, address)
+		if err != nil {
+			e.Close()
+			return nil, err
+		}
+		e.conns = append(e.conns, conn)
+	}
+
+	e.start()
+	return e, nil
+}
+
+// A pipe joins the standard output and input of a process.
+type pipe struct {
+	io.ReadCloser
+	io.WriteCloser
+}
+
+func (p pipe) Close() error {
+	p.WriteCloser.Close()
+	return p.ReadCloser.Close()
+}
+
+func newExternal(options ExternalOptions) (*External, error) {
+	e := &External{options: options}
+
+	switch options.Encoding {
+	case "", "text":
+		e.encode = func(bs bitset.BitSet) string { return fmt.Sprint(bs) }
+	case "hex":
+		e.encode = bitset.Hex
+	case "base64":
+		e.encode = bitset.Base64
+	default:
+		return nil, fmt.Errorf("problem: unknown solution encoding %q", options.Encoding)
+	}
+
+	if e.options.BatchSize < 1 {
+		e.options.BatchSize = 1
+	}
+	if e.options.Connections < 1 {
+		e.options.Connections = 1
+	}
+
+	return e, nil
+}
+
+// Function start runs the goroutines gathering requests into batches and
+// sending the batches over the connections.
+func (e *External) start() {
+	e.requests = make(chan []*request)
+	e.batches = make(chan []*request)
+
+	go e.gather()
+
+	for _, conn := range e.conns {
+		e.workers.Add(1)
+		go e.serve(conn)
+	}
+}
+
+// Function gather collects requests into batches of at most BatchSize
+// requests. A batch is sent when it is full, when BatchDelay has passed
+// since it was started, or without a delay when no request is waiting.
+func (e *External) gather() {
+	defer close(e.batches)
+
+	var pending []*request
+
+	for {
+		if len(pending) == 0 {
+			requests, ok := <-e.requests
+			if !ok {
+				return
+			}
+			pending = requests
+		}
+
+		var timeout <-chan time.Time
+		if e.options.BatchDelay > 0 {
+			timeout = time.After(e.options.BatchDelay)
+		}
+
+	collect:
+		for len(pending) < e.options.BatchSize {
+			if timeout == nil {
+				select {
+				case requests, ok := <-e.requests:
+					if !ok {
+						break collect
+					}
+					pending = append(pending, requests...)
+				default:
+					break collect
+				}
+				continue
+			}
+
+			select {
+			case requests, ok := <-e.requests:
+				if !ok {
+					break collect
+				}
+				pending = append(pending, requests...)
+			case <-timeout:
+				break collect
+			}
+		}
+
+		n := e.options.BatchSize
+		if len(pending) < n {
+			n = len(pending)
+		}
+		e.batches <- pending[:n:n]
+		pending = pending[n:]
+	}
+}
+
+// Function serve evaluates batches over a single connection.
+func (e *External) serve(conn io.ReadWriteCloser) {
+	defer e.workers.Done()
+
+	reader := bufio.NewReader(conn)
+	writer := bufio.NewWriter(conn)
+
+	for batch := range e.batches {
+		if e.Err() == nil {
+			if err := e.exchange(conn, reader, writer, batch); err != nil {
+				e.fail(err)
+			}
+		}
+
+		for _, r := range batch {
+			if e.Err() != nil {
+				r.fitness, r.optimal = math.Inf(-1), false
+			}
+			close(r.done)
+		}
+	}
+}
+
+// Function exchange sends a batch over the connection and reads back the
+// results into the requests. If the evaluator does not reply within the
+// timeout the connection is closed, failing the exchange.
+func (e *External) exchange(conn io.Closer, reader *bufio.Reader, writer *bufio.Writer, batch []*request) error {
+	var timer *time.Timer
+	if e.options.Timeout > 0 {
+		timer = time.AfterFunc(e.options.Timeout, func() { conn.Close() })
+	}
+
+	var err error
+	if e.options.JSON {
+		err = e.exchangeJSON(reader, writer, batch)
+	} else {
+		err = e.exchangeLines(reader, writer, batch)
+	}
+
+	// If the timer already fired, the connection was closed.
+	if timer != nil && !timer.Stop() {
+		return fmt.Errorf("problem: the evaluator did not reply within %v", e.options.Timeout)
+	}
+	return err
+}
+
+func (e *External) exchangeLines(reader *bufio.Reader, writer *bufio.Writer, batch []*request) error {
+	for _, r := range batch {
+		if _, err := fmt.Fprintln(writer, e.encode(r.bits)); err != nil {
+			return fmt.Errorf("problem: writing to the evaluator: %v", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("problem: writing to the evaluator: %v", err)
+	}
+
+	for _, r := range batch {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("problem: reading from the evaluator: %v", err)
+		}
+		if r.fitness, r.optimal, err = parseResult(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// The messages of the JSON protocol.
+type (
+	jsonRequest struct {
+		Solutions []string `json:"solutions"`
+	}
+
+	jsonResponse struct {
+		Fitness []float64 `json:"fitness"`
+		Optimal []bool    `json:"optimal,omitempty"`
+		Error   string    `json:"error,omitempty"`
+	}
+)
+
+func (e *External) exchangeJSON(reader *bufio.Reader, writer *bufio.Writer, batch []*request) error {
+	req := jsonRequest{Solutions: make([]string, len(batch))}
+	for i, r := range batch {
+		req.Solutions[i] = e.encode(r.bits)
+	}
+
+	if err := json.NewEncoder(writer).Encode(req); err != nil {
+		return fmt.Errorf("problem: writing to the evaluator: %v", err)
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("problem: writing to the evaluator: %v", err)
+	}
+
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		return fmt.Errorf("problem: reading from the evaluator: %v", err)
+	}
+
+	var resp jsonResponse
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return fmt.Errorf("problem: invalid evaluator result: %v", err)
+	}
+
+	switch {
+	case resp.Error != "":
+		return fmt.Errorf("problem: the evaluator failed: %s", resp.Error)
+	case len(resp.Fitness) != len(batch):
+		return fmt.Errorf("problem: the evaluator returned %d fitness values for %d solutions", len(resp.Fitness), len(batch))
+	case resp.Optimal != nil && len(resp.Optimal) != len(batch):
+		return fmt.Errorf("problem: the evaluator returned %d optimality values for %d solutions", len(resp.Optimal), len(batch))
+	}
+
+	for i, r := range batch {
+		r.fitness = resp.Fitness[i]
+		r.optimal = resp.Optimal != nil && resp.Optimal[i]
+	}
+	return nil
+}
+
+// Function parseResult parses a line holding a fitness value, optionally
+// followed by 1 or 0 for whether the solution is optimal.
+func parseResult(line string) (fitness float64, optimal bool, err error) {
+	fields := strings.Fields(line)
+	if len(fields) < 1 || len(fields) > 2 {
+		return 0, false, fmt.Errorf("problem: invalid evaluator result %q", line)
+	}
+
+	fitness, err = strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("problem: invalid evaluator fitness %q", fields[0])
+	}
+
+	if len(fields) == 2 {
+		switch fields[1] {
+		case "1":
+			optimal = true
+		case "0":
+		default:
+			return 0, false, fmt.Errorf("problem: invalid evaluator optimality %q", fields[1])
+		}
+	}
+
+	return fitness, optimal, nil
+}
+
+// Evaluate sends the solution to the evaluator in the next batch. It may be
+// called concurrently, which lets the solutions share batches.
+func (e *External) Evaluate(bits bitset.BitSet) (float64, bool) {
+	fitness, optimal := e.EvaluateBatch([]bitset.BitSet{bits})
+	return fitness[0], optimal[0]
+}
+
+// EvaluateBatch evaluates the solutions, which are split into batches of
+// at most BatchSize solutions.
+func (e *External) EvaluateBatch(solutions []bitset.BitSet) (fitness []float64, optimal []bool) {
+	fitness = make([]float64, len(solutions))
+	optimal = make([]bool, len(solutions))
+
+	e.mu.Lock()
+	closed := e.closed
+	e.mu.Unlock()
+
+	if closed || e.Err() != nil {
+		for i := range fitness {
+			fitness[i] = math.Inf(-1)
+		}
+		return fitness, optimal
+	}
+
+	requests := make([]*request, len(solutions))
+	for i, bits := range solutions {
+		requests[i] = &request{bits: bits, done: make(chan struct{})}
+	}
+	e.requests <- requests
+
+	for i, r := range requests {
+		<-r.done
+		fitness[i], optimal[i] = r.fitness, r.optimal
+	}
+	return fitness, optimal
+}
+
+func (e *External) fail(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.err == nil {
+		e.err = err
+	}
+}
+
+// Err returns the first failed evaluation.
+func (e *External) Err() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.err
+}
+
+// Close closes the connections and waits for the evaluator processes to
+// exit. Evaluate must not be called concurrently with or after Close.
+func (e *External) Close() error {
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return nil
+	}
+	e.closed = true
+	e.mu.Unlock()
+
+	if e.requests != nil {
+		close(e.requests)
+		e.workers.Wait()
+	}
+
+	var err error
+	for _, conn := range e.conns {
+		conn.Close()
+	}
+	for _, wait := range e.wait {
+		if waitErr := wait(); err == nil {
+			err = waitErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,180 @@
+package problem
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Morenim/gom-opencl/bitset"
+)
+
+// Function serveOneMax serves the OneMax function over the listener, with
+// the line or JSON protocol in the text encoding. It records the size of
+// every batch it receives.
+func serveOneMax(t *testing.T, listener net.Listener, useJSON bool, batches chan<- int) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go func(conn net.Conn) {
+			defer conn.Close()
+			scanner := bufio.NewScanner(conn)
+
+			for scanner.Scan() {
+				if !useJSON {
+					ones := strings.Count(scanner.Text(), "1")
+					if ones == len(scanner.Text()) {
+						fmt.Fprintf(conn, "%d 1\n", ones)
+					} else {
+						fmt.Fprintf(conn, "%d\n", ones)
+					}
+					continue
+				}
+
+				var req jsonRequest
+				if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+					t.Errorf("Invalid JSON request %q: %v", scanner.Text(), err)
+					return
+				}
+				batches <- len(req.Solutions)
+
+				var resp jsonResponse
+				for _, s := range req.Solutions {
+					ones := strings.Count(s, "1")
+					resp.Fitness = append(resp.Fitness, float64(ones))
+					resp.Optimal = append(resp.Optimal, ones == len(s))
+				}
+				json.NewEncoder(conn).Encode(resp)
+			}
+		}(conn)
+	}
+}
+
+func listen(t *testing.T) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() returned error %q.", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	return listener
+}
+
+var resultTests = []struct {
+	line     string
+	fitness  float64
+	optimal  bool
+	expected bool
+}{
+	{"12\n", 12, false, true},
+	{"-1.5 0\n", -1.5, false, true},
+	{"4 1\n", 4, true, true},
+	{"4 yes\n", 0, false, false},
+	{"\n", 0, false, false},
+	{"x\n", 0, false, false},
+}
+
+func TestParseResult(t *testing.T) {
+	for _, test := range resultTests {
+		fitness, optimal, err := parseResult(test.line)
+		if (err == nil) != test.expected || fitness != test.fitness || optimal != test.optimal {
+			t.Errorf("parseResult(%q) = %v, %v, %v, expected %v, %v, valid %t.",
+				test.line, fitness, optimal, err, test.fitness, test.optimal, test.expected)
+		}
+	}
+}
+
+func TestExternalLines(t *testing.T) {
+	listener := listen(t)
+	go serveOneMax(t, listener, false, nil)
+
+	options := ExternalOptions{Encoding: "text", BatchSize: 4, Connections: 2}
+	e, err := Dial(options, "tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial() returned error %q.", err)
+	}
+	defer e.Close()
+
+	solutions := []string{"0000", "0110", "1111", "1000", "0111", "1011"}
+	bits := make([]bitset.BitSet, len(solutions))
+	for i, s := range solutions {
+		bits[i], _ = bitset.FromString(s)
+	}
+
+	fitness, optimal := e.EvaluateBatch(bits)
+	for i, s := range solutions {
+		ones := strings.Count(s, "1")
+		if fitness[i] != float64(ones) || optimal[i] != (ones == 4) {
+			t.Errorf("EvaluateBatch(%s) = %v, %v, expected %d.", s, fitness[i], optimal[i], ones)
+		}
+	}
+
+	if err := e.Err(); err != nil {
+		t.Errorf("Err() = %q after successful evaluations.", err)
+	}
+}
+
+func TestExternalJSONBatches(t *testing.T) {
+	listener := listen(t)
+	batches := make(chan int, 100)
+	go serveOneMax(t, listener, true, batches)
+
+	options := ExternalOptions{Encoding: "text", JSON: true, BatchSize: 8, BatchDelay: 50 * time.Millisecond, Connections: 1}
+	e, err := Dial(options, "tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial() returned error %q.", err)
+	}
+	defer e.Close()
+
+	// Concurrent evaluations share batches.
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			bits := bitset.New(8)
+			bits.SetRange(0, i)
+			if fitness, _ := e.Evaluate(bits); fitness != float64(i) {
+				t.Errorf("Evaluate(%v) = %v, expected %d.", bits, fitness, i)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if n := <-batches; n < 2 {
+		t.Errorf("The first batch held %d solutions, expected concurrent solutions to share it.", n)
+	}
+}
+
+func TestExternalTimeout(t *testing.T) {
+	listener := listen(t)
+
+	// The evaluator accepts connections but never replies.
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	options := ExternalOptions{Encoding: "hex", BatchSize: 1, Timeout: 50 * time.Millisecond, Connections: 1}
+	e, err := Dial(options, "tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial() returned error %q.", err)
+	}
+	defer e.Close()
+
+	fitness, _ := e.Evaluate(bitset.New(8))
+	if e.Err() == nil || fitness > -1e300 {
+		t.Errorf("Evaluate() = %v, %v, expected a timeout.", fitness, e.Err())
+	}
+}
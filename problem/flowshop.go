@@ -0,0 +1,126 @@
+package problem
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// PermutationProblem is implemented by problems whose solutions are
+// permutations of the indices 0 to n-1.
+type PermutationProblem interface {
+	EvaluatePermutation(perm []int) (float64, bool)
+}
+
+// FlowShop is the permutation flow-shop scheduling problem: every job is
+// processed on the machines in order, and the jobs are processed in the
+// order of the permutation on every machine. The makespan, the completion
+// time of the last job on the last machine, is minimised.
+type FlowShop struct {
+	// Times holds the processing time of every job on every machine,
+	// indexed by machine first.
+	Times [][]float64
+
+	// LowerBound is a known lower bound on the makespan, or zero.
+	LowerBound float64
+}
+
+// RandomFlowShop returns a flow-shop instance with processing times drawn
+// uniformly from [1, 99], like the instances of Taillard.
+func RandomFlowShop(jobs, machines int, r *rand.Rand) *FlowShop {
+	f := &FlowShop{Times: make([][]float64, machines)}
+	for m := range f.Times {
+		f.Times[m] = make([]float64, jobs)
+		for j := range f.Times[m] {
+			f.Times[m][j] = float64(1 + r.Intn(99))
+		}
+	}
+	return f
+}
+
+// ReadFlowShop reads an instance in the format of Taillard. Lines with
+// letters are skipped. The first line of numbers holds the number of jobs
+// and machines, optionally followed by the seed, an upper bound and a
+// lower bound of the makespan. It is followed by the processing times of
+// every job, one machine after another.
+func ReadFlowShop(reader io.Reader) (*FlowShop, error) {
+	var header, times []float64
+
+	scanner := bufio.NewScanner(reader)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if strings.IndexFunc(text, isLetter) >= 0 {
+			continue
+		}
+
+		for _, field := range strings.Fields(text) {
+			v, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				return nil, fmt.Errorf("problem: line %d: %v", line, err)
+			}
+			times = append(times, v)
+		}
+
+		// The first line of numbers is the header.
+		if header == nil && len(times) > 0 {
+			header, times = times, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(header) < 2 {
+		return nil, fmt.Errorf("problem: missing the number of jobs and machines")
+	}
+	jobs, machines := int(header[0]), int(header[1])
+	if jobs < 1 || machines < 1 {
+		return nil, fmt.Errorf("problem: invalid flow-shop size %d x %d", jobs, machines)
+	}
+	if len(times) != jobs*machines {
+		return nil, fmt.Errorf("problem: read %d processing times, expected %d", len(times), jobs*machines)
+	}
+
+	f := &FlowShop{Times: make([][]float64, machines)}
+	for m := range f.Times {
+		f.Times[m] = times[m*jobs : (m+1)*jobs]
+	}
+	if len(header) >= 5 {
+		f.LowerBound = header[4]
+	}
+	return f, nil
+}
+
+func isLetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+// Jobs returns the number of jobs.
+func (f *FlowShop) Jobs() int {
+	return len(f.Times[0])
+}
+
+// Makespan returns the completion time of the last job of the permutation
+// on the last machine.
+func (f *FlowShop) Makespan(perm []int) float64 {
+	completion := make([]float64, len(f.Times))
+	for _, job := range perm {
+		previous := 0.0
+		for m := range f.Times {
+			completion[m] = math.Max(completion[m], previous) + f.Times[m][job]
+			previous = completion[m]
+		}
+	}
+	return completion[len(completion)-1]
+}
+
+// EvaluatePermutation returns the negated makespan, so the fitness is
+// maximised. A permutation is optimal if it attains the lower bound.
+func (f *FlowShop) EvaluatePermutation(perm []int) (float64, bool) {
+	makespan := f.Makespan(perm)
+	return -makespan, f.LowerBound > 0 && makespan <= f.LowerBound
+}
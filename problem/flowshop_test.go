@@ -0,0 +1,49 @@
+package problem
+
+import (
+	"strings"
+	"testing"
+)
+
+const taillard = `number of jobs, number of machines, initial seed, upper bound and lower bound :
+           3           2   873654221          12          11
+processing times :
+  3 2 4
+  1 5 2
+`
+
+func TestReadFlowShop(t *testing.T) {
+	f, err := ReadFlowShop(strings.NewReader(taillard))
+	if err != nil {
+		t.Fatalf("ReadFlowShop returned error %q.", err)
+	}
+	if f.Jobs() != 3 || len(f.Times) != 2 || f.LowerBound != 11 {
+		t.Fatalf("ReadFlowShop read %d jobs, %d machines, lower bound %v.", f.Jobs(), len(f.Times), f.LowerBound)
+	}
+
+	tests := []struct {
+		perm     []int
+		makespan float64
+		optimal  bool
+	}{
+		{[]int{0, 1, 2}, 12, false},
+		{[]int{1, 0, 2}, 11, true},
+		{[]int{0, 2, 1}, 14, false},
+		{[]int{2, 0, 1}, 14, false},
+	}
+
+	for _, test := range tests {
+		fitness, optimal := f.EvaluatePermutation(test.perm)
+		if fitness != -test.makespan || optimal != test.optimal {
+			t.Errorf("EvaluatePermutation(%v) = %v, %v, expected %v, %v.", test.perm, fitness, optimal, -test.makespan, test.optimal)
+		}
+	}
+}
+
+func TestReadFlowShopErrors(t *testing.T) {
+	for _, input := range []string{"", "3\n", "0 2\n", "3 2\n1 2 3\n", "3 2\n1 2 3 4 5 x6\n"} {
+		if _, err := ReadFlowShop(strings.NewReader(input)); err == nil {
+			t.Errorf("ReadFlowShop(%q) succeeded, expected an error.", input)
+		}
+	}
+}
@@ -31,3 +31,30 @@ func (_ HIFF) Evaluate(bits bitset.BitSet) (fitness float64, optimal bool) {
 
 	return
 }
+
+// Blocks returns every block of the hierarchy, from the pairs of adjacent
+// variables up to the block covering the whole bit-string.
+func (_ HIFF) Blocks(length int) [][]int {
+	var blocks [][]int
+
+	for blockSize := 2; blockSize <= length; blockSize *= 2 {
+		for i := 0; i+blockSize <= length; i += blockSize {
+			block := make([]int, blockSize)
+			for j := range block {
+				block[j] = i + j
+			}
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+// Optimum returns the fitness of the all-ones bit-string.
+func (h HIFF) Optimum(length int) float64 {
+	bits := bitset.New(length)
+	for i := 0; i < length; i++ {
+		bits.Set(i)
+	}
+	fitness, _ := h.Evaluate(bits)
+	return fitness
+}
@@ -0,0 +1,47 @@
+package problem
+
+import (
+	"github.com/Morenim/gom-opencl/bitset"
+)
+
+// KaryTrap is the deceptive trap function of size K over categorical
+// variables with values 0 to Alphabet-1, packed into fields of
+// bitset.FieldWidth(Alphabet) bits. A trap counts the variables set to the
+// highest value, so the optimum sets every variable to Alphabet-1.
+type KaryTrap struct {
+	Alphabet int
+	K        int
+}
+
+func (kt KaryTrap) Evaluate(bits bitset.BitSet) (fitness float64, optimal bool) {
+	width := bitset.FieldWidth(kt.Alphabet)
+	optimal = true
+
+	for i := 0; i < bits.Len()/width/kt.K; i++ {
+		t := 0 // number of variables set to the highest value
+		for j := 0; j < kt.K; j++ {
+			if bitset.Field(bits, i*kt.K+j, width) == kt.Alphabet-1 {
+				t++
+			}
+		}
+		if t == kt.K {
+			fitness += float64(t)
+		} else {
+			fitness += float64(kt.K - t - 1)
+			optimal = false
+		}
+	}
+	return
+}
+
+// Blocks returns the consecutive, non-overlapping traps of size K over a
+// problem of the given number of variables.
+func (kt KaryTrap) Blocks(length int) [][]int {
+	return DeceptiveTrap(kt.K).Blocks(length)
+}
+
+// Optimum returns the fitness of setting the given number of variables to
+// the highest value.
+func (kt KaryTrap) Optimum(length int) float64 {
+	return DeceptiveTrap(kt.K).Optimum(length)
+}
@@ -0,0 +1,33 @@
+package problem
+
+import (
+	"github.com/Morenim/gom-opencl/bitset"
+	"testing"
+)
+
+func TestKaryTrap(t *testing.T) {
+	kt := KaryTrap{Alphabet: 3, K: 2}
+
+	tests := []struct {
+		values  []int
+		fitness float64
+		optimal bool
+	}{
+		{[]int{2, 2, 2, 2}, 4, true},
+		{[]int{0, 0, 1, 1}, 2, false},
+		{[]int{2, 0, 1, 2}, 0, false},
+		{[]int{2, 2, 0, 1}, 3, false},
+	}
+
+	for _, test := range tests {
+		bits := bitset.New(2 * len(test.values))
+		for i, v := range test.values {
+			bitset.SetField(bits, i, 2, v)
+		}
+
+		fitness, optimal := kt.Evaluate(bits)
+		if fitness != test.fitness || optimal != test.optimal {
+			t.Errorf("Evaluate(%v) = %v, %v, expected %v, %v.", test.values, fitness, optimal, test.fitness, test.optimal)
+		}
+	}
+}
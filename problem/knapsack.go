@@ -0,0 +1,77 @@
+package problem
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/Morenim/gom-opencl/bitset"
+)
+
+// Knapsack is a 0/1 knapsack problem, where a solution selects the items
+// whose bits are set. As a constrained problem it maximises the total
+// profit, violated by the weight in excess of the capacity. As a
+// bi-objective problem it maximises the total profit and minimises the
+// total weight of the selected items.
+type Knapsack struct {
+	Profits  []float64
+	Weights  []float64
+	Capacity float64
+}
+
+// RandomKnapsack returns a knapsack of n items with profits and weights
+// drawn uniformly from [10, 100], and a capacity of half the total weight
+// rounded down.
+func RandomKnapsack(n int, r *rand.Rand) *Knapsack {
+	k := &Knapsack{Profits: make([]float64, n), Weights: make([]float64, n)}
+	for i := 0; i < n; i++ {
+		k.Profits[i] = float64(10 + r.Intn(91))
+		k.Weights[i] = float64(10 + r.Intn(91))
+		k.Capacity += k.Weights[i]
+	}
+	k.Capacity = math.Floor(k.Capacity / 2)
+	return k
+}
+
+// Function totals returns the total profit and weight of the selected
+// items.
+func (k *Knapsack) totals(bits bitset.BitSet) (profit, weight float64) {
+	for i := bits.NextSet(0); i >= 0 && i < len(k.Profits); i = bits.NextSet(i + 1) {
+		profit += k.Profits[i]
+		weight += k.Weights[i]
+	}
+	return profit, weight
+}
+
+// Evaluate returns the total profit of the selected items. No solution is
+// known to be optimal.
+func (k *Knapsack) Evaluate(bits bitset.BitSet) (float64, bool) {
+	profit, _ := k.totals(bits)
+	return profit, false
+}
+
+// Violation returns the weight of the selected items in excess of the
+// capacity.
+func (k *Knapsack) Violation(bits bitset.BitSet) float64 {
+	_, weight := k.totals(bits)
+	return math.Max(0, weight-k.Capacity)
+}
+
+func (k *Knapsack) Objectives() int {
+	return 2
+}
+
+// EvaluateObjectives returns the total profit and the negated total weight
+// of the selected items.
+func (k *Knapsack) EvaluateObjectives(bits bitset.BitSet) []float64 {
+	profit, weight := k.totals(bits)
+	return []float64{profit, -weight}
+}
+
+// Reference returns a point below the empty and the full knapsack.
+func (k *Knapsack) Reference(length int) []float64 {
+	total := 0.0
+	for _, w := range k.Weights {
+		total += w
+	}
+	return []float64{-1, -total - 1}
+}
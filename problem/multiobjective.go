@@ -0,0 +1,65 @@
+package problem
+
+import (
+	"github.com/Morenim/gom-opencl/bitset"
+)
+
+// MultiObjectiveProblem is implemented by problems with several objectives,
+// which are all maximised.
+type MultiObjectiveProblem interface {
+	Objectives() int
+	EvaluateObjectives(bits bitset.BitSet) []float64
+}
+
+// Referenced is implemented by multi-objective problems with a reference
+// point for the hypervolume, which every solution dominates.
+type Referenced interface {
+	Reference(length int) []float64
+}
+
+// TrapInverseTrap is the bi-objective problem of maximising the deceptive
+// trap function of size k over the ones and over the zeros of a
+// bit-string, whose Pareto front runs between the all-ones and the
+// all-zeros bit-string.
+type TrapInverseTrap int
+
+func (TrapInverseTrap) Objectives() int {
+	return 2
+}
+
+func (t TrapInverseTrap) EvaluateObjectives(bits bitset.BitSet) []float64 {
+	k := int(t)
+	objectives := make([]float64, 2)
+
+	for i := 0; i < bits.Len()/k; i++ {
+		ones := 0
+		for j := 0; j < k; j++ {
+			if bits.Has(i*k + j) {
+				ones++
+			}
+		}
+		objectives[0] += trap(ones, k)
+		objectives[1] += trap(k-ones, k)
+	}
+	return objectives
+}
+
+// Function trap returns the deceptive trap value of a block of size k
+// with the given number of ones.
+func trap(ones, k int) float64 {
+	if ones == k {
+		return float64(k)
+	}
+	return float64(k - ones - 1)
+}
+
+// Reference returns a point just below the lowest value of both
+// objectives.
+func (TrapInverseTrap) Reference(length int) []float64 {
+	return []float64{-1, -1}
+}
+
+// Blocks returns the consecutive, non-overlapping traps of size k.
+func (t TrapInverseTrap) Blocks(length int) [][]int {
+	return DeceptiveTrap(t).Blocks(length)
+}
@@ -7,3 +7,16 @@ import (
 type Problem interface {
 	Evaluate(bits bitset.BitSet) (float64, bool)
 }
+
+// Structured is implemented by problems whose building blocks are known in
+// advance. Blocks returns the ground-truth subsets of problem variables for
+// a problem of the given length, which are used to judge learned linkage.
+type Structured interface {
+	Blocks(length int) [][]int
+}
+
+// Bounded is implemented by problems with a known optimal fitness, so an
+// optimum can be detected from fitness values alone.
+type Bounded interface {
+	Optimum(length int) float64
+}
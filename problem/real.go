@@ -0,0 +1,84 @@
+package problem
+
+import (
+	"math"
+)
+
+// RealProblem is implemented by problems over vectors of reals, whose
+// objective value is minimised.
+type RealProblem interface {
+	Objective(x []float64) float64
+}
+
+// Sphere is the sum of the squared variables, with its optimum 0 at the
+// origin.
+type Sphere struct{}
+
+func (Sphere) Objective(x []float64) float64 {
+	sum := 0.0
+	for _, v := range x {
+		sum += v * v
+	}
+	return sum
+}
+
+// Rosenbrock is the generalised Rosenbrock function, with its optimum 0
+// where all variables are one.
+type Rosenbrock struct{}
+
+func (Rosenbrock) Objective(x []float64) float64 {
+	sum := 0.0
+	for i := 0; i+1 < len(x); i++ {
+		a, b := x[i+1]-x[i]*x[i], 1-x[i]
+		sum += 100*a*a + b*b
+	}
+	return sum
+}
+
+// RotatedEllipsoid is the ellipsoid function with condition number 10^6,
+// evaluated on the variables rotated by Rotation, so that all variables
+// depend on each other. Its optimum is 0 at the origin.
+type RotatedEllipsoid struct {
+	Rotation [][]float64
+}
+
+// NewRotatedEllipsoid returns the rotated ellipsoid of n variables, rotated
+// by 45 degrees in the plane of every pair of variables.
+func NewRotatedEllipsoid(n int) *RotatedEllipsoid {
+	rotation := make([][]float64, n)
+	for i := range rotation {
+		rotation[i] = make([]float64, n)
+		rotation[i][i] = 1
+	}
+
+	c, s := math.Cos(math.Pi/4), math.Sin(math.Pi/4)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			// Multiply the rows i and j by the rotation in their plane.
+			for k := 0; k < n; k++ {
+				a, b := rotation[i][k], rotation[j][k]
+				rotation[i][k], rotation[j][k] = c*a-s*b, s*a+c*b
+			}
+		}
+	}
+
+	return &RotatedEllipsoid{Rotation: rotation}
+}
+
+func (e *RotatedEllipsoid) Objective(x []float64) float64 {
+	n := len(x)
+	sum := 0.0
+	for i := 0; i < n; i++ {
+		y := 0.0
+		for j := 0; j < n; j++ {
+			y += e.Rotation[i][j] * x[j]
+		}
+
+		weight := 1.0
+		if n > 1 {
+			weight = math.Pow(10, 6*float64(i)/float64(n-1))
+		}
+		sum += weight * y * y
+	}
+	return sum
+}
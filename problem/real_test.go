@@ -0,0 +1,51 @@
+package problem
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRealObjectives(t *testing.T) {
+	ellipsoid := NewRotatedEllipsoid(3)
+
+	tests := []struct {
+		name     string
+		p        RealProblem
+		x        []float64
+		expected float64
+	}{
+		{"Sphere", Sphere{}, []float64{0, 0, 0}, 0},
+		{"Sphere", Sphere{}, []float64{1, -2, 3}, 14},
+		{"Rosenbrock", Rosenbrock{}, []float64{1, 1, 1}, 0},
+		{"Rosenbrock", Rosenbrock{}, []float64{0, 0, 0}, 2},
+		{"Rosenbrock", Rosenbrock{}, []float64{2, 1}, 901},
+		{"RotatedEllipsoid", ellipsoid, []float64{0, 0, 0}, 0},
+	}
+
+	for _, test := range tests {
+		if actual := test.p.Objective(test.x); math.Abs(actual-test.expected) > 1e-12 {
+			t.Errorf("%s.Objective(%v) = %v, expected %v.", test.name, test.x, actual, test.expected)
+		}
+	}
+}
+
+func TestRotationOrthogonal(t *testing.T) {
+	r := NewRotatedEllipsoid(5).Rotation
+
+	for i := range r {
+		for j := range r {
+			dot := 0.0
+			for k := range r {
+				dot += r[i][k] * r[j][k]
+			}
+
+			expected := 0.0
+			if i == j {
+				expected = 1
+			}
+			if math.Abs(dot-expected) > 1e-12 {
+				t.Errorf("Rows %d and %d of the rotation have dot product %v, expected %v.", i, j, dot, expected)
+			}
+		}
+	}
+}
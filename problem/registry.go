@@ -0,0 +1,48 @@
+package problem
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var registry = struct {
+	sync.Mutex
+	problems map[string]Problem
+}{problems: make(map[string]Problem)}
+
+// Register makes a problem available by name, e.g. for the -problem-evaluator
+// flag. Packages defining problems typically call it from an init function,
+// so importing the package registers its problems. Register panics if the
+// name is already taken.
+func Register(name string, p Problem) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	if _, ok := registry.problems[name]; ok {
+		panic(fmt.Sprintf("problem: Register called twice for %q", name))
+	}
+	registry.problems[name] = p
+}
+
+// Lookup returns the problem registered under the name.
+func Lookup(name string) (Problem, bool) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	p, ok := registry.problems[name]
+	return p, ok
+}
+
+// Registered returns the sorted names of all registered problems.
+func Registered() []string {
+	registry.Lock()
+	defer registry.Unlock()
+
+	names := make([]string, 0, len(registry.problems))
+	for name := range registry.problems {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
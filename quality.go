@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// LinkageQuality compares a learned FOS against the known building blocks
+// of a problem.
+type LinkageQuality struct {
+	// Found is the number of true blocks present as FOS nodes.
+	Found int
+	// Blocks is the number of true blocks.
+	Blocks int
+	// Spurious is the number of merges that created a subset which is
+	// neither part of a single block nor a union of complete blocks.
+	Spurious int
+}
+
+// Recall returns the fraction of true blocks present as FOS nodes.
+func (lq LinkageQuality) Recall() float64 {
+	if lq.Blocks == 0 {
+		return 1.0
+	}
+	return float64(lq.Found) / float64(lq.Blocks)
+}
+
+func (lq LinkageQuality) String() string {
+	return fmt.Sprintf("found %d/%d blocks (%.2f), %d spurious merges",
+		lq.Found, lq.Blocks, lq.Recall(), lq.Spurious)
+}
+
+// Function containsAll tests whether every index of subset is a member.
+func containsAll(member []bool, subset []int) bool {
+	for _, v := range subset {
+		if !member[v] {
+			return false
+		}
+	}
+	return true
+}
+
+// Function linkageQuality measures how well the FOS matches the blocks.
+// Every non-singleton subset of the FOS is counted as a merge, so the FOS
+// is expected to be a linkage tree over length problem variables.
+func linkageQuality(fos [][]int, blocks [][]int, length int) LinkageQuality {
+	lq := LinkageQuality{Blocks: len(blocks)}
+
+	member := make([]bool, length)
+	covered := make([]bool, length)
+
+	blockMembers := make([][]bool, len(blocks))
+	for i, block := range blocks {
+		blockMembers[i] = make([]bool, length)
+		for _, v := range block {
+			blockMembers[i][v] = true
+		}
+	}
+
+	for _, block := range blocks {
+		for _, subset := range fos {
+			if len(subset) == len(block) && IntArrayEquals(sortedCopy(subset), block) {
+				lq.Found++
+				break
+			}
+		}
+	}
+
+	for _, subset := range fos {
+		if len(subset) < 2 {
+			continue
+		}
+
+		for i := range member {
+			member[i], covered[i] = false, false
+		}
+		for _, v := range subset {
+			member[v] = true
+		}
+
+		// A merge is correct if the subset lies within a single block.
+		within := false
+
+		for i, block := range blocks {
+			if containsAll(blockMembers[i], subset) {
+				within = true
+				break
+			}
+
+			// Mark the variables of blocks completely inside the subset.
+			if containsAll(member, block) {
+				for _, v := range block {
+					covered[v] = true
+				}
+			}
+		}
+
+		if within {
+			continue
+		}
+
+		// Otherwise the subset must be made up of complete blocks.
+		if !containsAll(covered, subset) {
+			lq.Spurious++
+		}
+	}
+
+	return lq
+}
+
+// Function sortedCopy returns a sorted copy of the subset, leaving the
+// original order of the FOS intact.
+func sortedCopy(subset []int) []int {
+	s := append([]int(nil), subset...)
+	sort.Ints(s)
+	return s
+}
+
+// Function IntArrayEquals tests whether two slices hold the same values in
+// the same order.
+func IntArrayEquals(a []int, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i, v := range a {
+		if v != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/binary"
+	"github.com/Morenim/gom-opencl/ga"
+	"github.com/Morenim/gom-opencl/problem"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// Function residentData encodes the population and its fitness values as
+// the device returns them in resident mode.
+func residentData(pop *ga.Population, ft fitnessType) ([]uint32, []byte) {
+	data := make([]uint32, blocksPerSolution(pop)*pop.Size())
+	pop.PutUInt32s(data)
+
+	fitness := make([]byte, ft.size*pop.Size())
+	for i, solution := range pop.Solutions {
+		binary.NativeEndian.PutUint32(fitness[i*ft.size:], math.Float32bits(float32(solution.Fitness)))
+	}
+	return data, fitness
+}
+
+func TestResidentToPopulation(t *testing.T) {
+	trap := problem.DeceptiveTrap(4)
+	ft := findFitnessType("float")
+
+	r := rand.New(rand.NewSource(1))
+	device := ga.NewPopulation(8, 40, r)
+	for i := range device.Solutions {
+		device.Solutions[i].Fitness, _ = trap.Evaluate(device.Solutions[i].Bits)
+	}
+	device.Solutions[3].Bits.SetRange(0, 40)
+	device.Solutions[3].Fitness = trap.Optimum(40)
+
+	data, fitness := residentData(device, ft)
+	values := make([]float64, device.Size())
+	ft.decodeAll(fitness, values)
+
+	// The host population is stale until it mirrors the device.
+	pop := ga.NewPopulation(8, 40, r)
+	if !residentToPopulation(data, values, pop, trap.Optimum(40)) {
+		t.Errorf("residentToPopulation() missed the optimal solution.")
+	}
+	for i, solution := range pop.Solutions {
+		if !solution.Bits.Equal(device.Solutions[i].Bits) || solution.Fitness != device.Solutions[i].Fitness {
+			t.Errorf("Solution %d = %v, expected %v.", i, solution, device.Solutions[i])
+		}
+	}
+
+	// Clearing a bit of the optimum breaks a trap, which loses 4, so only a
+	// tolerance of 4 makes the best solution optimal.
+	device.Solutions[3].Bits.Clear(0)
+	device.Solutions[3].Fitness, _ = trap.Evaluate(device.Solutions[3].Bits)
+	data, fitness = residentData(device, ft)
+	ft.decodeAll(fitness, values)
+
+	if residentToPopulation(data, values, pop, trap.Optimum(40)) {
+		t.Errorf("residentToPopulation() found an optimum in a population without one.")
+	}
+
+	defer func(old float64) { tolerance = old }(tolerance)
+	tolerance = 4
+	if !residentToPopulation(data, values, pop, trap.Optimum(40)) {
+		t.Errorf("residentToPopulation() with tolerance 4 missed a solution within 4 of the optimum.")
+	}
+}
@@ -0,0 +1,366 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/Morenim/gom-opencl/ga"
+	"github.com/Morenim/gom-opencl/problem"
+)
+
+// RealEvaluator evaluates the objective values of many real-valued
+// solutions at once, on the host or on a compute device.
+type RealEvaluator interface {
+	EvaluateReal(xs [][]float64) []float64
+}
+
+// A hostRealEvaluator evaluates the solutions one by one on the host.
+type hostRealEvaluator struct {
+	problem problem.RealProblem
+}
+
+func (h hostRealEvaluator) EvaluateReal(xs [][]float64) []float64 {
+	objectives := make([]float64, len(xs))
+	for i, x := range xs {
+		objectives[i] = h.problem.Objective(x)
+	}
+	return objectives
+}
+
+// RealOptimizer performs real-valued GOMEA on the host. Every generation
+// it learns a linkage tree from the correlations between the variables of
+// the selected solutions, estimates a Gaussian distribution for every FOS
+// subset, and lets every solution except the best sample new values for
+// one subset after another, keeping the changes that do not lower its
+// fitness. Part of the samples are moved along the shift of the mean
+// since the previous generation, anticipating it. The fitness is the
+// negated objective value.
+type RealOptimizer struct {
+	Evaluator  RealEvaluator
+	Population *ga.RealPopulation
+	Rand       *rand.Rand
+
+	// SelectionRatio is the fraction of the best solutions from which the
+	// linkage tree and the distributions are estimated.
+	SelectionRatio float64
+
+	// The linkage model learned in the last generation.
+	FOS    [][]int
+	Merges []Merge
+
+	// Evaluations counts the evaluated solutions.
+	Evaluations int
+
+	// The distribution multiplier of every FOS subset, and the number of
+	// generations in which sampling it did not improve the best solution.
+	multipliers []float64
+	stalls      []int
+	best        float64
+
+	// The mean of the selected solutions in the previous generation.
+	previousMean []float64
+}
+
+// The adaptive variance scaling of the distribution multipliers.
+const (
+	multiplierIncrease = 1 / 0.9
+	multiplierDecrease = 0.9
+
+	// Samples of this fraction of the selection size are shifted by this
+	// multiple of the mean shift.
+	shiftRatio  = 0.5
+	shiftFactor = 2.0
+)
+
+// NewRealOptimizer returns an optimizer for the population, evaluating
+// every solution in the population.
+func NewRealOptimizer(evaluator RealEvaluator, pop *ga.RealPopulation, r *rand.Rand) *RealOptimizer {
+	o := &RealOptimizer{Evaluator: evaluator, Population: pop, Rand: r, SelectionRatio: 0.35}
+
+	xs := make([][]float64, pop.Size())
+	for i, solution := range pop.Solutions {
+		xs[i] = solution.Values
+	}
+	for i, objective := range o.evaluate(xs) {
+		pop.Solutions[i].Fitness = -objective
+	}
+	o.best = pop.Solutions[pop.Best()].Fitness
+
+	return o
+}
+
+// Function evaluate evaluates the solutions and counts the evaluations.
+func (o *RealOptimizer) evaluate(xs [][]float64) []float64 {
+	o.Evaluations += len(xs)
+	return o.Evaluator.EvaluateReal(xs)
+}
+
+// Step performs a single generation and reports whether the best solution
+// improved.
+func (o *RealOptimizer) Step() (improved bool) {
+	pop := o.Population
+
+	selected := o.selection()
+	o.Learn(selected)
+
+	if len(o.multipliers) != len(o.FOS) {
+		o.multipliers = make([]float64, len(o.FOS))
+		o.stalls = make([]int, len(o.FOS))
+		for k := range o.multipliers {
+			o.multipliers[k] = 1
+		}
+	}
+
+	shift := o.meanShift(selected)
+
+	// The best solution is kept, and the others mix in lockstep: all of
+	// them sample the same subset and are evaluated as a batch.
+	elite := pop.Best()
+
+	shifted := make([]bool, pop.Size())
+	for _, i := range o.Rand.Perm(pop.Size())[:int(shiftRatio*float64(len(selected)))] {
+		shifted[i] = i != elite
+	}
+
+	for k, subset := range o.FOS {
+		mean, cholesky := o.estimate(selected, subset)
+
+		var indices []int
+		var xs [][]float64
+		for i, solution := range pop.Solutions {
+			if i == elite {
+				continue
+			}
+			x := append([]float64(nil), solution.Values...)
+			o.sample(x, subset, mean, cholesky, o.multipliers[k])
+			if shifted[i] {
+				for _, j := range subset {
+					x[j] += shiftFactor * o.multipliers[k] * shift[j]
+				}
+			}
+			indices, xs = append(indices, i), append(xs, x)
+		}
+
+		better := false
+		for j, objective := range o.evaluate(xs) {
+			solution := &pop.Solutions[indices[j]]
+			if -objective >= solution.Fitness {
+				solution.Values, solution.Fitness = xs[j], -objective
+				if solution.Fitness > o.best {
+					o.best = solution.Fitness
+					better = true
+				}
+			}
+		}
+
+		o.adapt(k, better)
+		improved = improved || better
+	}
+
+	return improved
+}
+
+// Function selection returns the best solutions of the population in
+// order of decreasing fitness.
+func (o *RealOptimizer) selection() []ga.RealSolution {
+	order := make([]int, o.Population.Size())
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return o.Population.Solutions[order[a]].Fitness > o.Population.Solutions[order[b]].Fitness
+	})
+
+	n := int(math.Ceil(o.SelectionRatio * float64(len(order))))
+	if n < 2 {
+		n = 2
+	}
+	if n > len(order) {
+		n = len(order)
+	}
+
+	selected := make([]ga.RealSolution, n)
+	for i := range selected {
+		selected[i] = o.Population.Solutions[order[i]]
+	}
+	return selected
+}
+
+// Function meanShift returns the shift of the mean of the selected
+// solutions since the previous generation, which is zero in the first
+// generation.
+func (o *RealOptimizer) meanShift(selected []ga.RealSolution) []float64 {
+	mean := make([]float64, o.Population.Length())
+	for _, s := range selected {
+		for i, v := range s.Values {
+			mean[i] += v / float64(len(selected))
+		}
+	}
+
+	shift := make([]float64, len(mean))
+	if o.previousMean != nil {
+		for i := range shift {
+			shift[i] = mean[i] - o.previousMean[i]
+		}
+	}
+	o.previousMean = mean
+
+	return shift
+}
+
+// Learn learns the linkage tree of the selected solutions.
+func (o *RealOptimizer) Learn(selected []ga.RealSolution) {
+	o.FOS, o.Merges = clusterMatrix(correlationMatrix(selected), o.Rand)
+}
+
+// Function correlationMatrix computes the mutual information between
+// every pair of variables under a Gaussian model, -log(1 - rho^2) / 2 for
+// their correlation rho.
+func correlationMatrix(solutions []ga.RealSolution) *matrix {
+	n := len(solutions[0].Values)
+	m := float64(len(solutions))
+
+	mean := make([]float64, n)
+	for _, s := range solutions {
+		for i, v := range s.Values {
+			mean[i] += v / m
+		}
+	}
+
+	similarities := newMatrix(n)
+	for i := 0; i < n; i++ {
+		for j := 0; j <= i; j++ {
+			covariance, vi, vj := 0.0, 0.0, 0.0
+			for _, s := range solutions {
+				di, dj := s.Values[i]-mean[i], s.Values[j]-mean[j]
+				covariance += di * dj
+				vi += di * di
+				vj += dj * dj
+			}
+
+			rho := 0.0
+			if vi > 0 && vj > 0 {
+				rho = covariance / math.Sqrt(vi*vj)
+			}
+			rho2 := math.Min(rho*rho, 1-1e-12)
+			similarities.set(i, j, -math.Log(1-rho2)/2)
+		}
+	}
+
+	return similarities
+}
+
+// Function estimate returns the maximum-likelihood mean of the variables
+// of the subset in the selected solutions, and the Cholesky factor of
+// their covariance matrix.
+func (o *RealOptimizer) estimate(selected []ga.RealSolution, subset []int) ([]float64, [][]float64) {
+	n, m := len(subset), float64(len(selected))
+
+	mean := make([]float64, n)
+	for _, s := range selected {
+		for a, i := range subset {
+			mean[a] += s.Values[i] / m
+		}
+	}
+
+	covariance := make([][]float64, n)
+	for a, i := range subset {
+		covariance[a] = make([]float64, n)
+		for b, j := range subset[:a+1] {
+			sum := 0.0
+			for _, s := range selected {
+				sum += (s.Values[i] - mean[a]) * (s.Values[j] - mean[b])
+			}
+			covariance[a][b] = sum / m
+			covariance[b][a] = covariance[a][b]
+		}
+	}
+
+	return mean, cholesky(covariance)
+}
+
+// Function cholesky returns the lower-triangular Cholesky factor of the
+// covariance matrix. If the matrix is not positive definite, only its
+// diagonal is factored.
+func cholesky(c [][]float64) [][]float64 {
+	n := len(c)
+	l := make([][]float64, n)
+	for i := range l {
+		l[i] = make([]float64, n)
+	}
+
+	for i := 0; i < n; i++ {
+		for j := 0; j <= i; j++ {
+			sum := c[i][j]
+			for k := 0; k < j; k++ {
+				sum -= l[i][k] * l[j][k]
+			}
+
+			if i == j {
+				if sum <= 0 {
+					return diagonalCholesky(c)
+				}
+				l[i][i] = math.Sqrt(sum)
+			} else {
+				l[i][j] = sum / l[j][j]
+			}
+		}
+	}
+
+	return l
+}
+
+// Function diagonalCholesky factors the diagonal of the covariance matrix.
+func diagonalCholesky(c [][]float64) [][]float64 {
+	l := make([][]float64, len(c))
+	for i := range l {
+		l[i] = make([]float64, len(c))
+		l[i][i] = math.Sqrt(math.Max(c[i][i], 0))
+	}
+	return l
+}
+
+// Function sample overwrites the variables of the subset in x by a sample
+// of the Gaussian distribution with the mean and Cholesky factor, scaled
+// by the multiplier.
+func (o *RealOptimizer) sample(x []float64, subset []int, mean []float64, cholesky [][]float64, multiplier float64) {
+	z := make([]float64, len(subset))
+	for a := range z {
+		z[a] = o.Rand.NormFloat64()
+	}
+
+	for a, i := range subset {
+		v := 0.0
+		for b := 0; b <= a; b++ {
+			v += cholesky[a][b] * z[b]
+		}
+		x[i] = mean[a] + multiplier*v
+	}
+}
+
+// Function adapt scales the distribution multiplier of FOS subset k. It
+// grows while sampling improves the best solution, and otherwise shrinks
+// once it is above one or the subset stalled for 25 + n generations.
+func (o *RealOptimizer) adapt(k int, improved bool) {
+	if improved {
+		o.stalls[k] = 0
+		o.multipliers[k] = math.Max(o.multipliers[k], 1) * multiplierIncrease
+		return
+	}
+
+	if o.multipliers[k] <= 1 {
+		o.stalls[k]++
+	}
+	if o.multipliers[k] > 1 || o.stalls[k] >= 25+o.Population.Length() {
+		o.multipliers[k] *= multiplierDecrease
+	}
+	if o.multipliers[k] < 1 && o.stalls[k] < 25+o.Population.Length() {
+		o.multipliers[k] = 1
+	}
+}
+
+// Best returns the lowest objective value found.
+func (o *RealOptimizer) Best() float64 {
+	return -o.best
+}
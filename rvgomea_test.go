@@ -0,0 +1,66 @@
+package main
+
+import (
+	"github.com/Morenim/gom-opencl/ga"
+	"github.com/Morenim/gom-opencl/problem"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestCholesky(t *testing.T) {
+	c := [][]float64{{4, 2, 0}, {2, 5, 3}, {0, 3, 10}}
+	l := cholesky(c)
+
+	for i := range c {
+		for j := range c {
+			sum := 0.0
+			for k := range c {
+				sum += l[i][k] * l[j][k]
+			}
+			if math.Abs(sum-c[i][j]) > 1e-12 {
+				t.Errorf("cholesky(%v) = %v, whose product differs at (%d, %d).", c, l, i, j)
+			}
+		}
+	}
+}
+
+func TestCorrelationMatrix(t *testing.T) {
+	// Variables 0 and 1 are perfectly correlated, variable 2 is not.
+	solutions := []ga.RealSolution{
+		{Values: []float64{0, 1, 1}},
+		{Values: []float64{1, 3, -1}},
+		{Values: []float64{2, 5, -1}},
+		{Values: []float64{3, 7, 1}},
+	}
+
+	m := correlationMatrix(solutions)
+	if m.get(0, 1) <= 10 || m.get(0, 2) != 0 {
+		t.Errorf("correlationMatrix(%v) =\n%v", solutions, m)
+	}
+}
+
+func TestRealOptimizerSolves(t *testing.T) {
+	tests := []struct {
+		name string
+		p    problem.RealProblem
+	}{
+		{"Sphere", problem.Sphere{}},
+		{"Rosenbrock", problem.Rosenbrock{}},
+		{"RotatedEllipsoid", problem.NewRotatedEllipsoid(5)},
+	}
+
+	for _, test := range tests {
+		r := rand.New(rand.NewSource(1))
+		pop := ga.NewRealPopulation(100, 5, -5, 5, r)
+		optimizer := NewRealOptimizer(hostRealEvaluator{test.p}, pop, r)
+
+		for generation := 0; generation < 500 && optimizer.Best() > 1e-10; generation++ {
+			optimizer.Step()
+		}
+
+		if optimizer.Best() > 1e-10 {
+			t.Errorf("Optimizer reached %v on %s, expected at most 1e-10.", optimizer.Best(), test.name)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"math/rand"
+
+	"github.com/Morenim/gom-opencl/bitset"
+	"github.com/Morenim/gom-opencl/ga"
+	"github.com/Morenim/gom-opencl/problem"
+)
+
+// Crossover is the recombination operator of the simple GA.
+type Crossover int
+
+const (
+	// UniformCrossover exchanges every bit of two parents with probability
+	// one half.
+	UniformCrossover Crossover = iota
+
+	// OnePointCrossover exchanges the bits of two parents after a random
+	// cut point.
+	OnePointCrossover
+)
+
+// SimpleGA is a generational genetic algorithm with tournament selection,
+// crossover and bit-flip mutation, the classic baseline for GOMEA. All
+// randomness is drawn from Rand.
+type SimpleGA struct {
+	Problem    problem.Problem
+	Population *ga.Population
+	Rand       *rand.Rand
+
+	// Parents are the best of TournamentSize solutions drawn with
+	// replacement, and pairs of parents are recombined with probability
+	// CrossoverProbability. Every bit of the offspring is flipped with
+	// probability MutationRate.
+	TournamentSize       int
+	Crossover            Crossover
+	CrossoverProbability float64
+	MutationRate         float64
+
+	// Tournaments and improvements compare solutions by constraint
+	// domination up to Tolerance, like the mixing of Optimizer.
+	Tolerance float64
+
+	// Whether the solutions of the population are optimal, their
+	// constraint violations, and the best solution found so far.
+	optimal   []bool
+	violation []float64
+	elite     elite
+}
+
+// NewSimpleGA returns a simple GA with binary tournaments, uniform
+// crossover with probability 0.9 and a mutation rate of one over the
+// solution length, evaluating every solution in the population.
+func NewSimpleGA(p problem.Problem, pop *ga.Population, r *rand.Rand) *SimpleGA {
+	g := &SimpleGA{
+		Problem:              p,
+		Population:           pop,
+		Rand:                 r,
+		TournamentSize:       2,
+		Crossover:            UniformCrossover,
+		CrossoverProbability: 0.9,
+		MutationRate:         1 / float64(pop.Length()),
+	}
+	g.optimal, g.violation = evaluatePopulation(p, pop)
+	g.elite.update(pop, g.violation, g.Tolerance)
+	return g
+}
+
+// Optimal tests whether the population contains an optimal solution.
+func (g *SimpleGA) Optimal() bool {
+	return anyOptimal(g.optimal)
+}
+
+// Step performs a single generation: pairs of parents chosen by tournament
+// selection are recombined and mutated, and their offspring replace the
+// population. Step reports whether the best solution found so far improved
+// and whether an optimal solution was found.
+func (g *SimpleGA) Step() (improved, optimal bool) {
+	pop := g.Population
+	offspring := &ga.Population{Solutions: make([]ga.Solution, pop.Size())}
+
+	for i := 0; i < pop.Size(); i += 2 {
+		a := pop.Solutions[tournament(pop, g.violation, g.TournamentSize, g.Tolerance, g.Rand)].Bits.Clone()
+		b := pop.Solutions[tournament(pop, g.violation, g.TournamentSize, g.Tolerance, g.Rand)].Bits.Clone()
+
+		if g.Rand.Float64() < g.CrossoverProbability {
+			switch g.Crossover {
+			case UniformCrossover:
+				uniformCrossover(a, b, g.Rand)
+			case OnePointCrossover:
+				onePointCrossover(a, b, g.Rand)
+			}
+		}
+		mutate(a, g.MutationRate, g.Rand)
+		mutate(b, g.MutationRate, g.Rand)
+
+		// An odd population size drops the last offspring.
+		offspring.Solutions[i].Bits = a
+		if i+1 < pop.Size() {
+			offspring.Solutions[i+1].Bits = b
+		}
+	}
+
+	pop.Solutions = offspring.Solutions
+	g.optimal, g.violation = evaluatePopulation(g.Problem, pop)
+
+	return g.elite.update(pop, g.violation, g.Tolerance), g.Optimal()
+}
+
+// Function uniformCrossover exchanges every bit of a and b with probability
+// one half.
+func uniformCrossover(a, b bitset.BitSet, r *rand.Rand) {
+	for i := 0; i < a.Len(); i++ {
+		if a.Has(i) != b.Has(i) && r.Intn(2) == 0 {
+			a.Flip(i)
+			b.Flip(i)
+		}
+	}
+}
+
+// Function onePointCrossover exchanges the bits of a and b from a random
+// cut point to the end, where the cut leaves at least one bit on either
+// side.
+func onePointCrossover(a, b bitset.BitSet, r *rand.Rand) {
+	if a.Len() < 2 {
+		return
+	}
+	for i := 1 + r.Intn(a.Len()-1); i < a.Len(); i++ {
+		if a.Has(i) != b.Has(i) {
+			a.Flip(i)
+			b.Flip(i)
+		}
+	}
+}
+
+// Function mutate flips every bit with probability rate.
+func mutate(bits bitset.BitSet, rate float64, r *rand.Rand) {
+	for i := 0; i < bits.Len(); i++ {
+		if r.Float64() < rate {
+			bits.Flip(i)
+		}
+	}
+}
+
+// Function tournament returns the index of the best of size solutions drawn
+// from the population with replacement, compared by constraint domination.
+func tournament(pop *ga.Population, violation []float64, size int, tolerance float64, r *rand.Rand) int {
+	best := r.Intn(pop.Size())
+	for i := 1; i < size; i++ {
+		j := r.Intn(pop.Size())
+		if problem.ConstraintGt(pop.Solutions[j].Fitness, violation[j], pop.Solutions[best].Fitness, violation[best], tolerance) {
+			best = j
+		}
+	}
+	return best
+}
+
+// Function evaluatePopulation evaluates every solution of the population,
+// at once if the problem is Batched, and returns whether the solutions are
+// optimal and their constraint violations.
+func evaluatePopulation(p problem.Problem, pop *ga.Population) (optimal []bool, violation []float64) {
+	optimal = make([]bool, pop.Size())
+	violation = make([]float64, pop.Size())
+
+	for i, solution := range pop.Solutions {
+		violation[i] = problem.Violation(p, solution.Bits)
+	}
+
+	if batched, ok := p.(problem.Batched); ok {
+		solutions := make([]bitset.BitSet, pop.Size())
+		for i, solution := range pop.Solutions {
+			solutions[i] = solution.Bits
+		}
+
+		fitness, optimals := batched.EvaluateBatch(solutions)
+		for i := range pop.Solutions {
+			pop.Solutions[i].Fitness, optimal[i] = fitness[i], optimals[i]
+		}
+		return optimal, violation
+	}
+
+	for i, solution := range pop.Solutions {
+		pop.Solutions[i].Fitness, optimal[i] = p.Evaluate(solution.Bits)
+	}
+	return optimal, violation
+}
+
+// An elite is the fitness and violation of the best solution found so far
+// by a non-elitist algorithm, whose population may lose it.
+type elite struct {
+	fitness, violation float64
+	found              bool
+}
+
+// Function update records the best solution of the population if it is
+// better than the elite under constraint domination, and reports whether
+// it was.
+func (e *elite) update(pop *ga.Population, violation []float64, tolerance float64) bool {
+	best := dominant(pop, violation, tolerance)
+	fitness := pop.Solutions[best].Fitness
+	if e.found && !problem.ConstraintGt(fitness, violation[best], e.fitness, e.violation, tolerance) {
+		return false
+	}
+	e.fitness, e.violation, e.found = fitness, violation[best], true
+	return true
+}
+
+// Function dominant returns the index of the best solution of the
+// population under constraint domination.
+func dominant(pop *ga.Population, violation []float64, tolerance float64) int {
+	best := 0
+	for i := 1; i < pop.Size(); i++ {
+		if problem.ConstraintGt(pop.Solutions[i].Fitness, violation[i], pop.Solutions[best].Fitness, violation[best], tolerance) {
+			best = i
+		}
+	}
+	return best
+}
+
+// Function anyOptimal tests whether any of the solutions is optimal.
+func anyOptimal(optimal []bool) bool {
+	for _, o := range optimal {
+		if o {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"github.com/Morenim/gom-opencl/bitset"
+	"github.com/Morenim/gom-opencl/ga"
+	"github.com/Morenim/gom-opencl/problem"
+	"math/rand"
+	"testing"
+)
+
+func TestCrossoverPreservesBits(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for _, crossover := range []func(a, b bitset.BitSet, r *rand.Rand){uniformCrossover, onePointCrossover} {
+		for i := 0; i < 20; i++ {
+			a, b := bitset.New(40), bitset.New(40)
+			a.SetRange(0, 40)
+			crossover(a, b, r)
+
+			// Every bit is exchanged or kept, so the children stay
+			// complementary.
+			for j := 0; j < 40; j++ {
+				if a.Has(j) == b.Has(j) {
+					t.Fatalf("Crossover produced %v and %v, which agree on bit %d.", a, b, j)
+				}
+			}
+		}
+	}
+}
+
+func TestOnePointCrossover(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 20; i++ {
+		a, b := bitset.New(10), bitset.New(10)
+		a.SetRange(0, 10)
+		onePointCrossover(a, b, r)
+
+		// The bits of a are a non-empty prefix of ones followed by a
+		// non-empty suffix of zeros.
+		cut := a.Count()
+		if cut < 1 || cut > 9 || a.NextSet(cut) != -1 {
+			t.Errorf("onePointCrossover() produced %v, expected a single cut.", a)
+		}
+	}
+}
+
+func TestSimpleGASolvesOneMax(t *testing.T) {
+	for _, crossover := range []Crossover{UniformCrossover, OnePointCrossover} {
+		r := rand.New(rand.NewSource(1))
+		pop := ga.NewPopulation(100, 40, r)
+
+		// A trap of size 1 is OneMax.
+		g := NewSimpleGA(problem.DeceptiveTrap(1), pop, r)
+		g.Crossover = crossover
+
+		for generation := 0; generation < 200 && !g.Optimal(); generation++ {
+			g.Step()
+		}
+
+		if !g.Optimal() {
+			t.Errorf("SimpleGA with crossover %d did not solve OneMax, best fitness %v.", crossover, pop.BestFitness())
+		}
+	}
+}
+
+func TestTournamentPrefersFeasible(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	pop := ga.NewPopulation(2, 4, r)
+	pop.Solutions[0].Fitness, pop.Solutions[1].Fitness = 10, 1
+	violation := []float64{1, 0}
+
+	for i := 0; i < 20; i++ {
+		if winner := tournament(pop, violation, 8, 0, r); winner != 1 {
+			t.Fatalf("tournament() = %d, expected the feasible solution 1.", winner)
+		}
+	}
+}
+
+func TestEvolveBaselineStallWindow(t *testing.T) {
+	const stallLimit = 20
+
+	for seed := int64(1); seed <= 5; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		pop := ga.NewPopulation(200, 40, r)
+		g := NewSimpleGA(problem.DeceptiveTrap(4), pop, r)
+
+		// Single generations without improvement must not stop the run,
+		// which ends with an optimum or a full stall window.
+		generations := evolveBaseline(g, pop, stallLimit)
+		if !g.Optimal() && generations < stallLimit {
+			t.Errorf("evolveBaseline() with seed %d stopped after %d generations, expected at least %d.", seed, generations, stallLimit)
+		}
+	}
+}
+
+func TestEvolveBaselineSolvesOneMax(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	pop := ga.NewPopulation(100, 40, r)
+	g := NewSimpleGA(problem.DeceptiveTrap(1), pop, r)
+
+	if generations := evolveBaseline(g, pop, 20); !g.Optimal() {
+		t.Errorf("evolveBaseline() stopped after %d generations without solving OneMax, best fitness %v.", generations, pop.BestFitness())
+	}
+}
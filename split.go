@@ -0,0 +1,67 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// Function balance splits a population of the given size into one slice
+// per device, with slice sizes proportional to the measured throughput of
+// each device. Every device gets at least one solution if possible. The
+// returned counts sum to size.
+func balance(size int, throughput []float64) []int {
+	n := len(throughput)
+	counts := make([]int, n)
+
+	sum := 0.0
+	for _, t := range throughput {
+		sum += t
+	}
+
+	// Devices without a measurement yet are assumed to be equally fast.
+	if sum <= 0 || math.IsInf(sum, 0) || math.IsNaN(sum) {
+		throughput = make([]float64, n)
+		for i := range throughput {
+			throughput[i] = 1.0
+		}
+		sum = float64(n)
+	}
+
+	// Assign the integer parts, then the remainder by the largest fractions.
+	order := make([]int, n)
+	fractions := make([]float64, n)
+	assigned := 0
+	for i, t := range throughput {
+		share := float64(size) * t / sum
+		counts[i] = int(share)
+		fractions[i] = share - float64(counts[i])
+		assigned += counts[i]
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(a, b int) bool {
+		return fractions[order[a]] > fractions[order[b]]
+	})
+	for i := 0; assigned < size; i++ {
+		counts[order[i%n]]++
+		assigned++
+	}
+
+	// Move single solutions from the largest slices to empty slices.
+	if size >= n {
+		for i := range counts {
+			for counts[i] == 0 {
+				largest := 0
+				for j := range counts {
+					if counts[j] > counts[largest] {
+						largest = j
+					}
+				}
+				counts[largest]--
+				counts[i]++
+			}
+		}
+	}
+
+	return counts
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+)
+
+var balanceTests = []struct {
+	size       int
+	throughput []float64
+	expected   []int
+}{
+	{10, []float64{1}, []int{10}},
+	{10, []float64{0, 0}, []int{5, 5}},
+	{10, []float64{1, 1, 1}, []int{4, 3, 3}},
+	{100, []float64{3, 1}, []int{75, 25}},
+	{10, []float64{1000, 1, 1}, []int{8, 1, 1}},
+	{2, []float64{1, 1, 1}, []int{1, 1, 0}},
+}
+
+func TestBalance(t *testing.T) {
+	for _, test := range balanceTests {
+		actual := balance(test.size, test.throughput)
+		if !IntArrayEquals(actual, test.expected) {
+			t.Errorf("balance(%d, %v) = %v, expected %v.",
+				test.size, test.throughput, actual, test.expected)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"log"
+)
+
+// The number of generations without improvement of the best solution
+// after which real-valued GOMEA and the baselines terminate, as they often
+// fail to improve for a single generation.
+const maxStalls = 100
+
+// A stallWindow counts the generations since the best solution or the
+// population last improved.
+type stallWindow struct {
+	limit, stalls int
+}
+
+// Function update records whether the last generation improved, and
+// returns why the run stalled once it did not improve for limit
+// generations, or an empty string otherwise.
+func (w *stallWindow) update(improved bool) string {
+	if improved {
+		w.stalls = 0
+		return ""
+	}
+
+	w.stalls++
+	switch {
+	case w.stalls < w.limit:
+		return ""
+	case w.limit <= 1:
+		return "the population did not improve for one generation"
+	default:
+		return fmt.Sprintf("the best solution did not improve for %d generations", w.limit)
+	}
+}
+
+// Function terminated tests the termination criteria shared by all
+// algorithms after a generation: the generation limit, a stall for the
+// reason given by stalled, which is empty while the run progresses, a
+// converged population and an optimal solution.
+func terminated(generationsPassed int, stalled string, converged, foundOptimal bool) (done bool) {
+
+	if generationsPassed == numGenerations {
+		done = true
+	}
+
+	if stalled != "" {
+		if verbosity >= 2 {
+			log.Printf("Terminated after %s.", stalled)
+		}
+		done = true
+	}
+
+	if converged {
+		if verbosity >= 2 {
+			log.Println("Terminated after the population converged to a single solution.")
+		}
+		done = true
+	}
+
+	if foundOptimal {
+		if verbosity >= 2 {
+			log.Printf("Optimal solution found after %d generations.\n", generationsPassed)
+		}
+		done = true
+	}
+
+	return done
+}
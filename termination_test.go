@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestStallWindow(t *testing.T) {
+	for _, test := range []struct {
+		limit    int
+		improved []bool
+		stalled  int
+	}{
+		{1, []bool{true, true, false}, 2},
+		{3, []bool{false, false, true, false, false, true}, -1},
+		{3, []bool{false, true, false, false, false, false}, 4},
+	} {
+		window := stallWindow{limit: test.limit}
+
+		stalled := -1
+		for generation, improved := range test.improved {
+			if window.update(improved) != "" && stalled < 0 {
+				stalled = generation
+			}
+		}
+		if stalled != test.stalled {
+			t.Errorf("Window of %d over %v stalled in generation %d, expected %d.", test.limit, test.improved, stalled, test.stalled)
+		}
+	}
+}
+
+func TestTerminated(t *testing.T) {
+	defer func(old int) { numGenerations = old }(numGenerations)
+	numGenerations = 10
+
+	for _, test := range []struct {
+		generation         int
+		stalled            string
+		converged, optimal bool
+		expected           bool
+	}{
+		{1, "", false, false, false},
+		{10, "", false, false, true},
+		{11, "", false, false, false},
+		{1, "stalled", false, false, true},
+		{1, "", true, false, true},
+		{1, "", false, true, true},
+	} {
+		if actual := terminated(test.generation, test.stalled, test.converged, test.optimal); actual != test.expected {
+			t.Errorf("terminated(%d, %q, %t, %t) = %t, expected %t.", test.generation, test.stalled, test.converged, test.optimal, actual, test.expected)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"log"
+	"math"
+	"math/rand"
+
+	"github.com/Morenim/gom-opencl/ga"
+	"github.com/Morenim/gom-opencl/problem"
+)
+
+// Function fitnessAgrees tests whether fitness values computed on the host
+// and the device agree. Integer fitness values must be equal, while
+// floating-point values may differ by the tolerance and by rounding in the
+// precision of the fitness type.
+func fitnessAgrees(host, device float64, ft fitnessType, tolerance float64) bool {
+	if ft.integer {
+		return host == device
+	}
+
+	precision := 1e-12
+	if ft.name == "float" {
+		precision = 1e-5
+	}
+
+	return math.Abs(host-device) <= tolerance+precision*math.Max(1, math.Abs(host))
+}
+
+// Function verifyProblemKernel evaluates random solutions on the device and
+// the host and stops the program if their fitness values disagree, as the
+// problem kernel and its evaluator then implement different functions.
+func verifyProblemKernel(cd *computeDevice, p problem.Problem, ft fitnessType, samples, length int, r *rand.Rand) {
+	if samples <= 0 {
+		return
+	}
+
+	pop := ga.NewPopulation(samples, length, r)
+	data := make([]uint32, blocksPerSolution(pop)*samples)
+	pop.PutUInt32s(data)
+
+	device := cd.evaluate(data, samples, length, ft)
+
+	for i, solution := range pop.Solutions {
+		// A failed evaluator returns a meaningless fitness, so its error is
+		// reported instead of a disagreement.
+		host, _ := p.Evaluate(solution.Bits)
+		if fallible, ok := p.(problem.Fallible); ok && fallible.Err() != nil {
+			log.Fatalf("Fatal error: evaluation failed: %v", fallible.Err())
+		}
+		if !fitnessAgrees(host, device[i], ft, tolerance) {
+			log.Fatalf("Fatal error: the problem kernel and evaluator disagree on %v: device fitness %g, host fitness %g.",
+				solution.Bits, device[i], host)
+		}
+	}
+
+	if verbosity >= 2 {
+		log.Printf("The problem kernel and evaluator agree on %d random solutions.", samples)
+	}
+}
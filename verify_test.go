@@ -0,0 +1,29 @@
+package main
+
+import (
+	"testing"
+)
+
+var fitnessAgreesTests = []struct {
+	host, device float64
+	fitness      string
+	tolerance    float64
+	expected     bool
+}{
+	{12, 12, "uint", 0, true},
+	{12, 13, "int", 0.5, false},
+	{0.1, float64(float32(0.1)), "float", 0, true},
+	{0.1, float64(float32(0.1)), "double", 0, false},
+	{1, 1.01, "double", 0.02, true},
+	{1000, 1000.5, "float", 0, false},
+}
+
+func TestFitnessAgrees(t *testing.T) {
+	for _, test := range fitnessAgreesTests {
+		actual := fitnessAgrees(test.host, test.device, findFitnessType(test.fitness), test.tolerance)
+		if actual != test.expected {
+			t.Errorf("fitnessAgrees(%g, %g, %s, %g) = %t, expected %t.",
+				test.host, test.device, test.fitness, test.tolerance, actual, test.expected)
+		}
+	}
+}